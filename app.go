@@ -0,0 +1,401 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nikitaSstepanov/tools/client/pg"
+	"github.com/nikitaSstepanov/tools/client/redis"
+	e "github.com/nikitaSstepanov/tools/error"
+	"github.com/nikitaSstepanov/tools/health"
+	"github.com/nikitaSstepanov/tools/httper"
+	"github.com/nikitaSstepanov/tools/sl"
+)
+
+// AppCfg holds the settings of the application lifecycle manager.
+type AppCfg struct {
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT" env-default:"15s" validate:"required,min=1s" env-description:"Time given to all components to stop"`
+}
+
+// Component is a resource whose lifetime is managed by App.
+type Component interface {
+	// Start is called once, after all dependencies of the component were started.
+	Start(ctx context.Context) error
+
+	// Stop is called once, before any of the component's dependencies are stopped.
+	// The context carries the global shutdown deadline.
+	Stop(ctx context.Context) error
+}
+
+// Notifier may be implemented by a Component that can fail after Start,
+// e.g. a server whose listener died. An error received from Notify stops the App.
+type Notifier interface {
+	Notify() <-chan error
+}
+
+// Hook adapts plain functions to the Component interface.
+// Both functions are optional.
+type Hook struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+func (h Hook) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+
+	return h.OnStart(ctx)
+}
+
+func (h Hook) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+
+	return h.OnStop(ctx)
+}
+
+// App starts registered components in dependency order, waits for a shutdown
+// signal and stops them in reverse order within a global deadline.
+type App struct {
+	components      []*component
+	started         []*component
+	shutdownTimeout time.Duration
+	health          *health.Registry
+	tools           *Tools
+	failed          chan error
+	mu              sync.Mutex
+}
+
+type component struct {
+	name string
+	deps []string
+	c    Component
+	// opened is set for components whose resource was opened at registration,
+	// so Stop closes them even if they were never started.
+	opened bool
+}
+
+// NewApp returns an App of the default instance.
+func NewApp() *App {
+	return std.NewApp()
+}
+
+// NewApp returns an App configured from the "app" section of the config.
+// Its factory methods build clients of the instance.
+func (t *Tools) NewApp() *App {
+	app := newApp(&t.config.App, t.health)
+	app.tools = t
+
+	return app
+}
+
+func newApp(cfg *AppCfg, reg *health.Registry) *App {
+	return &App{
+		components:      make([]*component, 0),
+		started:         make([]*component, 0),
+		shutdownTimeout: cfg.ShutdownTimeout,
+		health:          reg,
+		failed:          make(chan error, 1),
+	}
+}
+
+// Register adds a component to the App. Components are started in registration
+// order, except that a component is always started after the ones named in deps.
+func (a *App) Register(name string, c Component, deps ...string) {
+	a.register(name, c, false, deps)
+}
+
+func (a *App) register(name string, c Component, opened bool, deps []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.components = append(a.components, &component{
+		name:   name,
+		deps:   deps,
+		c:      c,
+		opened: opened,
+	})
+}
+
+// Pg connects to postgres and registers the pool as the "postgres" component.
+func (a *App) Pg(deps ...string) (pg.Client, error) {
+	postgres, err := a.tools.Pg()
+	if err != nil {
+		return nil, err
+	}
+
+	a.register("postgres", Hook{
+		OnStop: func(_ context.Context) error {
+			postgres.Close()
+			return nil
+		},
+	}, true, deps)
+
+	return postgres, nil
+}
+
+// Redis connects to redis and registers the client as the "redis" component.
+func (a *App) Redis(deps ...string) (redis.Client, error) {
+	rs, err := a.tools.Redis()
+	if err != nil {
+		return redis.Client{}, err
+	}
+
+	a.register("redis", Hook{
+		OnStop: func(_ context.Context) error {
+			return rs.Close()
+		},
+	}, true, deps)
+
+	return rs, nil
+}
+
+// Sl builds the logger and registers it as the "logger" component,
+// which closes the log file on shutdown.
+func (a *App) Sl(deps ...string) *sl.Logger {
+	logger, closer := sl.Open(&a.tools.config.Logger)
+
+	a.register("logger", Hook{
+		OnStop: func(_ context.Context) error {
+			return closer.Close()
+		},
+	}, true, deps)
+
+	return logger
+}
+
+// HttpServer builds the server and registers it as the "http_server" component.
+// The server starts listening in App.Start and is shut down in App.Stop.
+func (a *App) HttpServer(handler http.Handler, deps ...string) *httper.Server {
+	server := a.tools.HttpServer(handler)
+
+	a.Register("http_server", &serverComponent{server: server}, deps...)
+
+	return server
+}
+
+// Run starts the App, blocks until ctx is done, SIGINT/SIGTERM is received
+// or a component fails, and then stops the App. The failure of a component
+// is returned joined with the errors of stopping.
+func (a *App) Run(ctx context.Context) error {
+	if err := a.Start(ctx); err != nil {
+		return err
+	}
+
+	failErr := a.Wait(ctx)
+
+	return errors.Join(failErr, a.Stop())
+}
+
+// Start starts all components in dependency order. If a component fails to
+// start, the already started ones are stopped and the error is returned.
+func (a *App) Start(ctx context.Context) error {
+	a.mu.Lock()
+	order, err := a.order()
+	a.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	for _, comp := range order {
+		if err := comp.c.Start(ctx); err != nil {
+			startErr := fmt.Errorf("start %s: %w", comp.name, err)
+
+			if stopErr := a.Stop(); stopErr != nil {
+				return errors.Join(startErr, stopErr)
+			}
+
+			return startErr
+		}
+
+		a.mu.Lock()
+		a.started = append(a.started, comp)
+		a.mu.Unlock()
+
+		if n, ok := comp.c.(Notifier); ok {
+			go a.watch(comp.name, n)
+		}
+	}
+
+	return nil
+}
+
+// Wait blocks until ctx is done, SIGINT/SIGTERM is received or a started component fails.
+// It returns the error of the failed component and nil otherwise.
+func (a *App) Wait(ctx context.Context) error {
+	log := sl.L(ctx)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	select {
+	case s := <-interrupt:
+		log.Info("signal: " + s.String())
+	case err := <-a.failed:
+		log.Error("app: " + err.Error())
+
+		return err
+	case <-ctx.Done():
+		log.Info("app: context done")
+	}
+
+	return nil
+}
+
+// Stop marks the health registry as not ready, writes the errors suppressed by
+// e.SetSampling while the log is still open and stops the started components
+// in reverse order. Resources opened by Pg, Redis and Sl are closed even if
+// their component was never started. All of them share one deadline of
+// ShutdownTimeout; errors are joined.
+func (a *App) Stop() error {
+	a.health.SetReady(false)
+	e.FlushSuppressed()
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	a.mu.Lock()
+	stopping := a.stopping()
+	a.mu.Unlock()
+
+	var errs []error
+
+	for _, comp := range stopping {
+
+		if err := comp.c.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", comp.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stopping returns the components to stop, in order, and forgets them:
+// the opened but never started ones in reverse registration order, since
+// they may depend on started ones, then the started ones in reverse start order.
+// It must be called with a.mu held.
+func (a *App) stopping() []*component {
+	stopping := make([]*component, 0, len(a.started))
+	started := make(map[*component]bool, len(a.started))
+
+	for _, comp := range a.started {
+		started[comp] = true
+	}
+
+	for i := len(a.components) - 1; i >= 0; i-- {
+		comp := a.components[i]
+
+		if comp.opened && !started[comp] {
+			stopping = append(stopping, comp)
+		}
+
+		comp.opened = false
+	}
+
+	for i := len(a.started) - 1; i >= 0; i-- {
+		stopping = append(stopping, a.started[i])
+	}
+
+	a.started = make([]*component, 0)
+
+	return stopping
+}
+
+func (a *App) watch(name string, n Notifier) {
+	err, ok := <-n.Notify()
+	if !ok || err == nil || errors.Is(err, http.ErrServerClosed) {
+		return
+	}
+
+	select {
+	case a.failed <- fmt.Errorf("%s: %w", name, err):
+	default:
+	}
+}
+
+// order sorts components topologically, keeping registration order
+// among components that don't depend on each other.
+func (a *App) order() ([]*component, error) {
+	byName := make(map[string]*component, len(a.components))
+
+	for _, comp := range a.components {
+		if _, ok := byName[comp.name]; ok {
+			return nil, fmt.Errorf("component %s is registered twice", comp.name)
+		}
+
+		byName[comp.name] = comp
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+
+	state := make(map[string]int, len(a.components))
+	order := make([]*component, 0, len(a.components))
+
+	var visit func(comp *component) error
+
+	visit = func(comp *component) error {
+		switch state[comp.name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle at component %s", comp.name)
+		case done:
+			return nil
+		}
+
+		state[comp.name] = visiting
+
+		for _, dep := range comp.deps {
+			d, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("component %s depends on unknown component %s", comp.name, dep)
+			}
+
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+
+		state[comp.name] = done
+		order = append(order, comp)
+
+		return nil
+	}
+
+	for _, comp := range a.components {
+		if err := visit(comp); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+type serverComponent struct {
+	server *httper.Server
+}
+
+func (s *serverComponent) Start(_ context.Context) error {
+	s.server.Start()
+	return nil
+}
+
+func (s *serverComponent) Stop(ctx context.Context) error {
+	return s.server.Stop(ctx)
+}
+
+func (s *serverComponent) Notify() <-chan error {
+	return s.server.Notify()
+}
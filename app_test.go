@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	e "github.com/nikitaSstepanov/tools/error"
+	"github.com/nikitaSstepanov/tools/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordHook(name string, events *[]string, startErr error) Hook {
+	return Hook{
+		OnStart: func(_ context.Context) error {
+			*events = append(*events, "start "+name)
+			return startErr
+		},
+		OnStop: func(_ context.Context) error {
+			*events = append(*events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestAppOrder(t *testing.T) {
+	events := make([]string, 0)
+
+	app := newApp(&AppCfg{ShutdownTimeout: time.Second}, health.New())
+	app.Register("server", recordHook("server", &events, nil), "postgres", "redis")
+	app.Register("postgres", recordHook("postgres", &events, nil), "logger")
+	app.Register("redis", recordHook("redis", &events, nil))
+	app.Register("logger", recordHook("logger", &events, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := app.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"start logger", "start postgres", "start redis", "start server",
+		"stop server", "stop redis", "stop postgres", "stop logger",
+	}, events)
+}
+
+func TestAppStartFailure(t *testing.T) {
+	events := make([]string, 0)
+	startErr := errors.New("connection refused")
+
+	app := newApp(&AppCfg{ShutdownTimeout: time.Second}, health.New())
+	app.Register("logger", recordHook("logger", &events, nil))
+	app.Register("postgres", recordHook("postgres", &events, startErr), "logger")
+	app.Register("server", recordHook("server", &events, nil), "postgres")
+
+	err := app.Start(context.Background())
+
+	assert.ErrorIs(t, err, startErr)
+	assert.Equal(t, []string{"start logger", "start postgres", "stop logger"}, events)
+}
+
+func TestAppDependencyErrors(t *testing.T) {
+	app := newApp(&AppCfg{}, health.New())
+	app.Register("a", Hook{}, "b")
+	app.Register("b", Hook{}, "a")
+
+	assert.Error(t, app.Start(context.Background()))
+
+	app = newApp(&AppCfg{}, health.New())
+	app.Register("a", Hook{}, "missing")
+
+	assert.Error(t, app.Start(context.Background()))
+
+	app = newApp(&AppCfg{}, health.New())
+	app.Register("a", Hook{})
+	app.Register("a", Hook{})
+
+	assert.Error(t, app.Start(context.Background()))
+}
+
+type failingComponent struct {
+	Hook
+	notify chan error
+}
+
+func (f *failingComponent) Notify() <-chan error {
+	return f.notify
+}
+
+func TestAppComponentFailure(t *testing.T) {
+	comp := &failingComponent{notify: make(chan error, 1)}
+	comp.OnStop = func(_ context.Context) error {
+		return errors.New("queue not drained")
+	}
+
+	app := newApp(&AppCfg{ShutdownTimeout: time.Second}, health.New())
+	app.Register("worker", comp)
+
+	comp.notify <- errors.New("listener died")
+
+	done := make(chan error)
+
+	go func() {
+		done <- app.Run(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		assert.EqualError(t, err, "worker: listener died\nstop worker: queue not drained")
+	case <-time.After(time.Second):
+		t.Fatal("app did not stop after component failure")
+	}
+}
+
+func TestAppStopNotReady(t *testing.T) {
+	reg := health.New()
+
+	app := newApp(&AppCfg{ShutdownTimeout: time.Second}, reg)
+
+	assert.NoError(t, app.Start(context.Background()))
+	assert.True(t, reg.Run(context.Background()).Ready)
+
+	assert.NoError(t, app.Stop())
+	assert.False(t, reg.Run(context.Background()).Ready)
+}
+
+func TestAppStopOpened(t *testing.T) {
+	events := make([]string, 0)
+
+	app := newApp(&AppCfg{ShutdownTimeout: time.Second}, health.New())
+	app.register("logger", recordHook("logger", &events, nil), true, nil)
+	app.register("postgres", recordHook("postgres", &events, nil), true, []string{"logger"})
+	app.Register("server", recordHook("server", &events, nil), "postgres")
+
+	assert.NoError(t, app.Stop())
+	assert.NoError(t, app.Stop())
+	assert.Equal(t, []string{"stop postgres", "stop logger"}, events)
+}
+
+func TestAppStartFailureOpened(t *testing.T) {
+	events := make([]string, 0)
+	startErr := errors.New("connection refused")
+
+	app := newApp(&AppCfg{ShutdownTimeout: time.Second}, health.New())
+	app.register("logger", recordHook("logger", &events, nil), true, nil)
+	app.Register("cache", recordHook("cache", &events, startErr), "logger")
+	app.register("postgres", recordHook("postgres", &events, nil), true, []string{"logger"})
+
+	err := app.Start(context.Background())
+
+	assert.ErrorIs(t, err, startErr)
+	assert.Equal(t, []string{"start logger", "start cache", "stop postgres", "stop logger"}, events)
+	assert.NoError(t, app.Stop())
+	assert.Len(t, events, 4)
+}
+
+func TestAppStopFlushSuppressed(t *testing.T) {
+	var buff bytes.Buffer
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buff, nil)))
+
+	e.SetSampling(time.Minute, 1)
+
+	t.Cleanup(func() {
+		e.SetSampling(0, 0)
+		slog.SetDefault(prev)
+	})
+
+	for i := 0; i < 3; i++ {
+		e.New("App sampling test.", e.Internal).Log()
+	}
+
+	app := newApp(&AppCfg{ShutdownTimeout: time.Second}, health.New())
+
+	assert.NoError(t, app.Stop())
+	assert.Contains(t, buff.String(), `"msg":"suppressed 2 times"`)
+}
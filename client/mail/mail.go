@@ -1,18 +1,20 @@
 package mail
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/smtp"
 )
 
 // Config holds the configuration for the email client.
 // It includes fields for the mail server host, port, username, password, and identity.
 type Config struct {
-	Host     string `yaml:"host"     env:"MAIL_HOST"`     // The SMTP server hostname
-	Port     int    `yaml:"port"     env:"MAIL_PORT"`     // The SMTP server port
-	Username string `yaml:"username" env:"MAIL_USERNAME"` // The username for authentication
-	Password string `env:"MAIL_PASSWORD" `                // The password for authentication
-	Identity string `yaml:"identity" env:"MAIL_IDENTITY"` // The identity of the sender
+	Host     string `yaml:"host"     env:"MAIL_HOST" env-description:"SMTP server host"`                               // The SMTP server hostname
+	Port     int    `yaml:"port"     env:"MAIL_PORT" validate:"min=1,max=65535" env-description:"SMTP server port"`    // The SMTP server port
+	Username string `yaml:"username" env:"MAIL_USERNAME" env-description:"SMTP user, also used as the sender address"` // The username for authentication
+	Password string `env:"MAIL_PASSWORD" secret:"true" env-description:"SMTP password"`                                // The password for authentication
+	Identity string `yaml:"identity" env:"MAIL_IDENTITY" env-description:"SMTP identity"`                              // The identity of the sender
 }
 
 // Client represents an email client that can send emails using the specified configuration.
@@ -36,6 +38,33 @@ func New(cfg *Config) *Client {
 	}
 }
 
+// Ping connects to the SMTP server and checks it with the NOOP command.
+func (c *Client) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", c.host, c.port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Noop(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
 // Send sends an email to the specified recipient with the given message, subject, and content type.
 // It uses SMTP authentication and constructs the email message in the required format.
 func (c *Client) Send(to string, message string, subject string, contentType string) error {
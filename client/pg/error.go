@@ -0,0 +1,107 @@
+package pg
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	e "github.com/nikitaSstepanov/tools/error"
+)
+
+type PgError = pgconn.PgError
+
+// ForeignKeyErr is the Conflict error of foreign key violations:
+// the referenced row is missing or the deleted one is still referenced.
+var ForeignKeyErr = e.ConflictErr.
+	WithMessage("Related record is missing or still in use.").
+	WithKey("errors.foreign_key", nil)
+
+var (
+	statuses = map[string]e.StatusType{
+		"23505": e.Conflict, // unique_violation
+		"23503": e.Conflict, // foreign_key_violation
+		"23502": e.BadInput, // not_null_violation
+		"23514": e.BadInput, // check_violation
+		"22P02": e.BadInput, // invalid_text_representation
+		"22001": e.BadInput, // string_data_right_truncation
+		"22003": e.BadInput, // numeric_value_out_of_range
+	}
+
+	statusMu sync.RWMutex
+)
+
+func init() {
+	e.RegisterTranslator(Translate)
+}
+
+// MapCode sets the status that e.E gives to postgres errors with the SQLSTATE code.
+func MapCode(sqlstate string, status e.StatusType) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+
+	statuses[sqlstate] = status
+}
+
+// Translate converts ErrNoRows to NotFound and postgres errors with a mapped SQLSTATE
+// code to their status. The constraint, table and column of the error are tagged
+// as the e.LogOnly group "db", so they are logged but never sent to clients.
+// It returns nil for other errors. Translate is registered in e, so e.E applies it.
+func Translate(err error) e.Error {
+	if errors.Is(err, ErrNoRows) {
+		return e.NotFoundErr.WithErr(err)
+	}
+
+	var pgErr *PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	statusMu.RLock()
+	status, ok := statuses[pgErr.Code]
+	statusMu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	var result e.Error
+
+	switch {
+
+	case pgErr.Code == "23503" && status == e.Conflict:
+		result = ForeignKeyErr.WithErr(err)
+
+	case status == e.Conflict:
+		result = e.ConflictErr.WithErr(err)
+
+	case status == e.BadInput:
+		result = e.BadInputErr.WithErr(err)
+
+	case status == e.NotFound:
+		result = e.NotFoundErr.WithErr(err)
+
+	default:
+		result = e.New("", status, err)
+
+	}
+
+	tags := map[string]string{
+		"constraint": pgErr.ConstraintName,
+		"table":      pgErr.TableName,
+		"column":     pgErr.ColumnName,
+	}
+
+	db := make(e.LogOnly, len(tags))
+
+	for key, value := range tags {
+		if value != "" {
+			db[key] = value
+		}
+	}
+
+	if len(db) != 0 {
+		result = result.WithTag("db", db)
+	}
+
+	return result
+}
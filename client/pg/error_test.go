@@ -0,0 +1,95 @@
+package pg
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	e "github.com/nikitaSstepanov/tools/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status e.StatusType
+		db     e.LogOnly
+	}{
+		{
+			name:   "No rows",
+			err:    fmt.Errorf("get user: %w", ErrNoRows),
+			status: e.NotFound,
+		},
+		{
+			name:   "Unique violation",
+			err:    &PgError{Code: "23505", ConstraintName: "users_email_key", TableName: "users"},
+			status: e.Conflict,
+			db:     e.LogOnly{"constraint": "users_email_key", "table": "users"},
+		},
+		{
+			name:   "Foreign key violation",
+			err:    &PgError{Code: "23503", ConstraintName: "orders_user_id_fkey", TableName: "orders"},
+			status: e.Conflict,
+			db:     e.LogOnly{"constraint": "orders_user_id_fkey", "table": "orders"},
+		},
+		{
+			name:   "Not null violation",
+			err:    &PgError{Code: "23502", TableName: "users", ColumnName: "email"},
+			status: e.BadInput,
+			db:     e.LogOnly{"table": "users", "column": "email"},
+		},
+		{
+			name:   "Unmapped code",
+			err:    &PgError{Code: "40001"},
+			status: e.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.E(tt.err)
+
+			assert.Equal(t, tt.status, err.GetCode())
+			assert.ErrorIs(t, err, tt.err)
+
+			if tt.db != nil {
+				assert.Equal(t, tt.db, err.GetTag("db"))
+			}
+
+			assert.Empty(t, err.ToProblem().Extensions)
+		})
+	}
+
+	assert.Nil(t, Translate(fmt.Errorf("dial tcp: connection refused")))
+
+	fk := e.E(&PgError{Code: "23503"})
+	_, _, line, _ := runtime.Caller(0)
+
+	assert.Equal(t, "github.com/nikitaSstepanov/tools/client/pg.TestTranslate", fk.GetStack()[0].Function)
+	assert.Equal(t, line-1, fk.GetStack()[0].Line)
+
+	assert.ErrorIs(t, fk, ForeignKeyErr)
+	assert.ErrorIs(t, fk, e.ConflictErr)
+	assert.Equal(t, "errors.foreign_key", fk.GetKey())
+	assert.Equal(t, ForeignKeyErr.GetMessage(), fk.PublicMessage(""))
+
+	unique := e.E(&PgError{Code: "23505"})
+
+	assert.ErrorIs(t, unique, e.ConflictErr)
+	assert.NotErrorIs(t, unique, ForeignKeyErr)
+	assert.NotErrorIs(t, fk, e.E(&PgError{Code: "23503"}))
+}
+
+func TestMapCode(t *testing.T) {
+	MapCode("40001", e.Unavailable)
+	defer func() {
+		statusMu.Lock()
+		delete(statuses, "40001")
+		statusMu.Unlock()
+	}()
+
+	err := e.E(&PgError{Code: "40001"})
+
+	assert.Equal(t, e.Unavailable, err.GetCode())
+}
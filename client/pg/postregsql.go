@@ -19,14 +19,14 @@ type Client interface {
 
 // Config is type for database connection.
 type Config struct {
-	Host           string `yaml:"host"     env:"PG_HOST"    env-default:"localhost"`
-	Port           int    `yaml:"port"     env:"PG_PORT"    env-default:"5432"`
-	DBName         string `yaml:"dbname"   env:"PG_NAME"    env-default:"postgres"`
-	Username       string `yaml:"username" env:"PG_USER"`
-	Password       string `env:"POSTGRES_PASSWORD"`
-	SSLMode        string `yaml:"sslmode"  env:"PG_SSLMODE" env-default:"disabled"`
-	MigrationsRun  bool   `yaml:"migrations_run" env:"PG_MIGRATIONS_RUN" env-default:"false"`
-	MigrationsPath string `yaml:"migrations_path" env:"PG_MIGRATIONS_PATH"`
+	Host           string `yaml:"host"     env:"PG_HOST"    env-default:"localhost" env-description:"Postgres host"`
+	Port           int    `yaml:"port"     env:"PG_PORT"    env-default:"5432" validate:"min=1,max=65535" env-description:"Postgres port"`
+	DBName         string `yaml:"dbname"   env:"PG_NAME"    env-default:"postgres" env-description:"Postgres database name"`
+	Username       string `yaml:"username" env:"PG_USER" env-description:"Postgres user"`
+	Password       string `env:"POSTGRES_PASSWORD" secret:"true" env-description:"Postgres password"`
+	SSLMode        string `yaml:"sslmode"  env:"PG_SSLMODE" env-default:"disable" validate:"oneof=disable allow prefer require verify-ca verify-full" env-description:"Postgres SSL mode"`
+	MigrationsRun  bool   `yaml:"migrations_run" env:"PG_MIGRATIONS_RUN" env-default:"false" env-description:"Apply migrations on connect"`
+	MigrationsPath string `yaml:"migrations_path" env:"PG_MIGRATIONS_PATH" env-description:"Directory with migrations"`
 }
 
 type pgclient struct {
@@ -0,0 +1,21 @@
+package redis
+
+import (
+	"errors"
+
+	e "github.com/nikitaSstepanov/tools/error"
+)
+
+func init() {
+	e.RegisterTranslator(Translate)
+}
+
+// Translate converts Nil, returned for missing keys, to NotFound.
+// It returns nil for other errors. Translate is registered in e, so e.E applies it.
+func Translate(err error) e.Error {
+	if errors.Is(err, Nil) {
+		return e.NotFoundErr.WithErr(err)
+	}
+
+	return nil
+}
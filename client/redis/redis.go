@@ -9,10 +9,10 @@ import (
 
 // Config is type for database connection
 type Config struct {
-	Host     string `yaml:"host" env:"REDIS_HOST"      env-default:"localhost"`
-	Port     int    `yaml:"port" env:"REDIS_PORT"      env-default:"6379"`
-	DBNumber int    `yaml:"db"   env:"REDIS_DB_NUMBER" env-default:"0"`
-	Password string `env:"REDIS_PASSWORD"`
+	Host     string `yaml:"host" env:"REDIS_HOST"      env-default:"localhost" env-description:"Redis host"`
+	Port     int    `yaml:"port" env:"REDIS_PORT"      env-default:"6379" validate:"min=1,max=65535" env-description:"Redis port"`
+	DBNumber int    `yaml:"db"   env:"REDIS_DB_NUMBER" env-default:"0"    validate:"min=0" env-description:"Redis database number"`
+	Password string `env:"REDIS_PASSWORD" secret:"true" env-description:"Redis password"`
 }
 
 func getConfig(cfg *Config) *redis.Options {
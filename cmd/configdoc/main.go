@@ -0,0 +1,66 @@
+// Command configdoc writes an example config.yaml, a .env.example and a
+// markdown table of environment variables for tools.Config, the config read by
+// tools.Init. httper.ClientCfg, which applications load themselves, is described
+// separately in HTTP_CLIENT.md.
+//
+//	go run github.com/nikitaSstepanov/tools/cmd/configdoc -out docs
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/nikitaSstepanov/tools"
+	config "github.com/nikitaSstepanov/tools/configurator"
+	"github.com/nikitaSstepanov/tools/httper"
+)
+
+func main() {
+	out := flag.String("out", ".", "directory to write the files to")
+	flag.Parse()
+
+	files := map[string]func() ([]byte, error){
+		"config.example.yaml": func() ([]byte, error) {
+			return config.ExampleYaml(&tools.Config{})
+		},
+		".env.example": func() ([]byte, error) {
+			return config.EnvExample(&tools.Config{})
+		},
+		"CONFIG.md": func() ([]byte, error) {
+			return config.EnvTable(&tools.Config{})
+		},
+		"HTTP_CLIENT.md": httpClientTable,
+	}
+
+	if err := os.MkdirAll(*out, os.ModePerm); err != nil {
+		log.Fatal(err)
+	}
+
+	for name, generate := range files {
+		data, err := generate()
+		if err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(*out, name), data, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// httpClientTable describes httper.ClientCfg. tools.Init doesn't read it,
+// so its keys are relative to the section the application loads it from.
+func httpClientTable() ([]byte, error) {
+	table, err := config.EnvTable(&httper.ClientCfg{})
+	if err != nil {
+		return nil, err
+	}
+
+	header := "# httper.ClientCfg\n\n" +
+		"Not read by tools.Init. Load it into your own config, e.g. with `config.Section[httper.ClientCfg](\"payments_api\")` of package configurator; " +
+		"YAML keys are relative to that section.\n\n"
+
+	return append([]byte(header), table...), nil
+}
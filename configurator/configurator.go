@@ -1,10 +1,77 @@
 package config
 
 import (
-	"github.com/ilyakaznacheev/cleanenv"
+	"os"
+	"reflect"
+	"sync"
+
 	"github.com/nikitaSstepanov/tools"
+	"github.com/nikitaSstepanov/tools/configurator/loader"
+)
+
+type (
+	ValidationError = loader.ValidationError
+	FieldError      = loader.FieldError
 )
 
+// resolved keeps the paths read from references for each cfg passed to Get,
+// so that Dump of the same cfg redacts them.
+var resolved sync.Map
+
+// Get reads tools.ConfigPath of the default instance, its APP_ENV overlay and environment variables into cfg,
+// with the same precedence as tools.Init. Invalid fields are reported together as *ValidationError.
 func Get(cfg interface{}) error {
-	return cleanenv.ReadConfig(tools.ConfigPath, cfg)
+	paths, err := read(cfg)
+	if err != nil {
+		return err
+	}
+
+	resolved.Store(cfg, paths)
+
+	return nil
+}
+
+func read(cfg interface{}) (loader.Resolved, error) {
+	opts := loader.Options{
+		Env: os.Getenv(loader.EnvVar),
+	}
+
+	return loader.Read(tools.ConfigPath, cfg, opts)
+}
+
+// Section reads only the subtree under key, e.g. "postgres" or "payments.stripe",
+// into a new T, applying environment variables from the env tags of T.
+// It lets a library own its config block without knowing the root struct.
+func Section[T any](key string) (T, error) {
+	var cfg T
+
+	opts := loader.Options{
+		Env: os.Getenv(loader.EnvVar),
+	}
+
+	_, err := loader.ReadSection(tools.ConfigPath, key, &cfg, opts)
+
+	return cfg, err
+}
+
+// SectionOf is Section for the config file of t.
+func SectionOf[T any](t *tools.Tools, key string) (T, error) {
+	var cfg T
+
+	err := t.Section(key, &cfg)
+
+	return cfg, err
+}
+
+// Dump returns cfg as YAML with fields tagged `secret:"true"` redacted.
+// If cfg was filled by Get, values read from references are redacted as well.
+func Dump(cfg interface{}) ([]byte, error) {
+	var paths loader.Resolved
+
+	if cfg != nil && reflect.TypeOf(cfg).Comparable() {
+		r, _ := resolved.Load(cfg)
+		paths, _ = r.(loader.Resolved)
+	}
+
+	return loader.Dump(cfg, paths)
 }
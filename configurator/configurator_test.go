@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/nikitaSstepanov/tools"
+	"github.com/nikitaSstepanov/tools/client/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPayments struct {
+	Key      string `yaml:"key"      env:"TEST_PAYMENTS_KEY"`
+	Retries  int    `yaml:"retries"  env:"TEST_PAYMENTS_RETRIES" env-default:"3"`
+	Currency string `yaml:"currency" env:"TEST_PAYMENTS_CURRENCY" env-default:"RUB"`
+}
+
+func TestSection(t *testing.T) {
+	useConfig(t, `
+redis:
+  host: cache
+payments:
+  key: pk_test
+  stripe:
+    key: sk_test
+    retries: 5
+`)
+
+	t.Setenv("TEST_PAYMENTS_CURRENCY", "USD")
+
+	payments, err := Section[testPayments]("payments")
+
+	assert.NoError(t, err)
+	assert.Equal(t, testPayments{Key: "pk_test", Retries: 3, Currency: "USD"}, payments)
+
+	stripe, err := Section[testPayments]("payments.stripe")
+
+	assert.NoError(t, err)
+	assert.Equal(t, testPayments{Key: "sk_test", Retries: 5, Currency: "USD"}, stripe)
+
+	rs, err := Section[redis.Config]("redis")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cache", rs.Host)
+	assert.Equal(t, 6379, rs.Port)
+}
+
+func TestSectionErrors(t *testing.T) {
+	useConfig(t, "payments:\n  key: pk_test\n  retries: many\n")
+
+	_, err := Section[testPayments]("payments")
+
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	assert.Equal(t, "payments", vErr.Errors[0].Section)
+	assert.Equal(t, "payments.retries", vErr.Errors[0].Path)
+}
+
+func TestSectionOf(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	writeFile(t, path, "payments:\n  key: pk_live\n")
+
+	instance, err := tools.New(tools.WithConfigPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payments, err := SectionOf[testPayments](instance, "payments")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pk_live", payments.Key)
+}
+
+func TestGetDump(t *testing.T) {
+	useConfig(t, "logger:\n  level: ${TEST_LOG_LEVEL}\ncache:\n  size: 2\n")
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+
+	var cfg testConfig
+
+	assert.NoError(t, Get(&cfg))
+	assert.Equal(t, "debug", cfg.Logger.Level)
+
+	data, err := Dump(&cfg)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "level: <redacted>")
+	assert.Contains(t, string(data), "size: 2")
+}
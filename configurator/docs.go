@@ -0,0 +1,196 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/nikitaSstepanov/tools/configurator/loader"
+	"gopkg.in/yaml.v3"
+)
+
+// ExampleYaml returns an example config file for cfg with default values.
+// Every key is commented with its description, environment variable and rules.
+// Fields that can only be set from the environment, like passwords, are left out.
+func ExampleYaml(cfg interface{}) ([]byte, error) {
+	fields, err := loader.Describe(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	for _, f := range fields {
+		if !f.InYaml {
+			continue
+		}
+
+		value := &yaml.Node{
+			Kind:        yaml.ScalarNode,
+			Value:       exampleValue(f),
+			LineComment: comment(f, true),
+		}
+
+		if f.Type == "string" {
+			value.Tag = "!!str"
+		}
+
+		insert(root, strings.Split(f.Path, "."), value)
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EnvExample returns an example .env file for cfg with default values.
+// Secrets are left empty.
+func EnvExample(cfg interface{}) ([]byte, error) {
+	fields, err := loader.Describe(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	section := ""
+
+	for i, f := range fields {
+		if len(f.Envs) == 0 {
+			continue
+		}
+
+		if s, _, _ := strings.Cut(f.Path, "."); i == 0 || s != section {
+			section = s
+
+			if buf.Len() != 0 {
+				buf.WriteString("\n")
+			}
+
+			buf.WriteString("# " + section + "\n")
+		}
+
+		if c := comment(f, false); c != "" {
+			buf.WriteString("# " + c + "\n")
+		}
+
+		buf.WriteString(f.Envs[0] + "=" + exampleValue(f) + "\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EnvTable returns a markdown table of all environment variables of cfg.
+func EnvTable(cfg interface{}) ([]byte, error) {
+	fields, err := loader.Describe(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("| Variable | YAML key | Type | Default | Description |\n")
+	buf.WriteString("|---|---|---|---|---|\n")
+
+	for _, f := range fields {
+		if len(f.Envs) == 0 {
+			continue
+		}
+
+		key := "—"
+		if f.InYaml {
+			key = "`" + f.Path + "`"
+		}
+
+		def := ""
+		if f.HasDefault && f.Default != "" {
+			def = "`" + f.Default + "`"
+		}
+
+		desc := make([]string, 0)
+
+		if f.Description != "" {
+			desc = append(desc, f.Description+".")
+		}
+
+		if f.Required {
+			desc = append(desc, "Required.")
+		}
+
+		if f.Rules != "" {
+			desc = append(desc, "Rules: `"+f.Rules+"`.")
+		}
+
+		if f.Secret {
+			desc = append(desc, "Secret.")
+		}
+
+		fmt.Fprintf(&buf, "| `%s` | %s | %s | %s | %s |\n",
+			strings.Join(f.Envs, "`, `"), key, f.Type, def, strings.ReplaceAll(strings.Join(desc, " "), "|", "\\|"))
+	}
+
+	return buf.Bytes(), nil
+}
+
+func exampleValue(f loader.Field) string {
+	if f.Secret || !f.HasDefault {
+		return ""
+	}
+
+	return f.Default
+}
+
+func comment(f loader.Field, withEnv bool) string {
+	parts := make([]string, 0)
+
+	if f.Description != "" {
+		parts = append(parts, f.Description)
+	}
+
+	if withEnv && len(f.Envs) != 0 {
+		parts = append(parts, "env "+f.Envs[0])
+	}
+
+	if f.Rules != "" {
+		parts = append(parts, f.Rules)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// insert puts value into root under the path, creating mappings on the way.
+func insert(root *yaml.Node, path []string, value *yaml.Node) {
+	node := root
+
+	for i, name := range path {
+		var next *yaml.Node
+
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == name {
+				next = node.Content[j+1]
+				break
+			}
+		}
+
+		if next == nil {
+			next = value
+
+			if i != len(path)-1 {
+				next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+
+			node.Content = append(node.Content, key, next)
+		}
+
+		node = next
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nikitaSstepanov/tools/client/pg"
+	"github.com/nikitaSstepanov/tools/configurator/loader"
+	"github.com/nikitaSstepanov/tools/httper"
+	"github.com/stretchr/testify/assert"
+)
+
+type docsConfig struct {
+	Postgres   pg.Config        `yaml:"postgres"`
+	HttpServer httper.ServerCfg `yaml:"http_server"`
+}
+
+func TestExampleYaml(t *testing.T) {
+	data, err := ExampleYaml(&docsConfig{})
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(data), "port: 5432 # Postgres port; env PG_PORT; min=1,max=65535")
+	assert.NotContains(t, string(data), "password")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var fromExample, fromDefaults docsConfig
+
+	_, err = loader.Read(path, &fromExample, loader.Options{Strict: true})
+	assert.NoError(t, err)
+
+	if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loader.Read(path, &fromDefaults, loader.Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, fromDefaults, fromExample)
+}
+
+func TestEnvExample(t *testing.T) {
+	data, err := EnvExample(&docsConfig{})
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(data), "# postgres\n# Postgres host\nPG_HOST=localhost\n")
+	assert.Contains(t, string(data), "\nPOSTGRES_PASSWORD=\n")
+	assert.Contains(t, string(data), "\n\n# http_server\n")
+}
+
+func TestEnvTable(t *testing.T) {
+	data, err := EnvTable(&docsConfig{})
+	assert.NoError(t, err)
+
+	assert.Contains(t, string(data), "| `PG_PORT` | `postgres.port` | int | `5432` | Postgres port. Rules: `min=1,max=65535`. |\n")
+	assert.Contains(t, string(data), "| `POSTGRES_PASSWORD` | — | string |  | Postgres password. Secret. |\n")
+}
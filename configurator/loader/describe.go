@@ -0,0 +1,67 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Field describes one config value, as used for generated documentation.
+type Field struct {
+	// Path is the dotted YAML path of the field.
+	Path string
+
+	// InYaml is false for fields without an explicit yaml tag,
+	// which are meant to be set from the environment only (e.g. passwords).
+	InYaml bool
+
+	Envs        []string
+	Type        string
+	Default     string
+	HasDefault  bool
+	Description string
+	Required    bool
+	Secret      bool
+	Rules       string
+}
+
+// Describe returns descriptions of all fields of cfg, which must be a struct or a pointer to one.
+func Describe(cfg interface{}) ([]Field, error) {
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a struct, got %T", cfg)
+	}
+
+	result := make([]Field, 0)
+
+	for _, f := range fields(v) {
+		def, hasDefault := f.tag.Lookup("env-default")
+		_, required := f.tag.Lookup("env-required")
+		rules := f.tag.Get("validate")
+
+		result = append(result, Field{
+			Path:        f.name(),
+			InYaml:      f.tag.Get("yaml") != "",
+			Envs:        f.envs,
+			Type:        f.value.Type().String(),
+			Default:     def,
+			HasDefault:  hasDefault,
+			Description: f.tag.Get("env-description"),
+			Required:    required || hasRule(rules, "required"),
+			Secret:      isSecret(f.tag),
+			Rules:       rules,
+		})
+	}
+
+	return result, nil
+}
+
+func hasRule(rules string, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if rule == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+package loader
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces secret values in dumps.
+const Redacted = "<redacted>"
+
+// Dump returns cfg encoded as YAML. Fields tagged `secret:"true"` and fields
+// at the resolved paths, as returned by Read, are replaced with Redacted unless they are empty.
+func Dump(cfg interface{}, resolved Resolved) ([]byte, error) {
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a struct, got %T", cfg)
+	}
+
+	node, err := dumpStruct(v, resolved, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(node)
+}
+
+func dumpStruct(v reflect.Value, resolved Resolved, prefix string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, ok := YamlName(sf)
+		if !ok {
+			continue
+		}
+
+		path := prefix + name
+
+		var value *yaml.Node
+		var err error
+
+		switch {
+		case isNested(sf.Type):
+			value, err = dumpStruct(v.Field(i), resolved, path+".")
+		case (isSecret(sf.Tag) || resolved[path]) && !v.Field(i).IsZero():
+			value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: Redacted}
+		default:
+			value = &yaml.Node{}
+			err = value.Encode(v.Field(i).Interface())
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+
+		node.Content = append(node.Content, key, value)
+	}
+
+	return node, nil
+}
+
+func isSecret(tag reflect.StructTag) bool {
+	return tag.Get("secret") == "true"
+}
@@ -0,0 +1,97 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// checkEnv reports environment variables and defaults that can't be parsed
+// into their fields, and required fields that have no value at all.
+// Values are applied afterwards by cleanenv, which stops at the first error.
+func checkEnv(v reflect.Value, errs *[]FieldError) {
+	for _, f := range fields(v) {
+		env, raw, ok := lookupEnv(f.envs)
+
+		if !ok {
+			if !f.value.IsZero() {
+				continue
+			}
+
+			def, hasDefault := f.tag.Lookup("env-default")
+
+			if _, required := f.tag.Lookup("env-required"); required {
+				*errs = append(*errs, f.errorf("value is required"+envHint(f.envs)))
+				continue
+			}
+
+			if !hasDefault {
+				continue
+			}
+
+			if err := checkValue(f.value.Type(), def); err != nil {
+				*errs = append(*errs, f.errorf(fmt.Sprintf("invalid default %q: %s", def, err)))
+			}
+
+			continue
+		}
+
+		if err := checkValue(f.value.Type(), raw); err != nil {
+			*errs = append(*errs, f.errorf(fmt.Sprintf("invalid value of %s: %s", env, err)))
+		}
+	}
+}
+
+func lookupEnv(envs []string) (string, string, bool) {
+	for _, env := range envs {
+		if value, ok := os.LookupEnv(env); ok {
+			return env, value, true
+		}
+	}
+
+	return "", "", false
+}
+
+func envHint(envs []string) string {
+	if len(envs) == 0 {
+		return ""
+	}
+
+	return " (env " + envs[0] + ")"
+}
+
+// checkValue parses raw the way cleanenv does for basic kinds.
+// Other kinds are left to cleanenv.
+func checkValue(t reflect.Type, raw string) error {
+	var err error
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	if t == durationType {
+		_, err = time.ParseDuration(raw)
+		return err
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		_, err = strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(raw, 0, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(raw, 0, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(raw, t.Bits())
+	}
+
+	if numErr, ok := err.(*strconv.NumError); ok {
+		return numErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,95 @@
+package loader
+
+import (
+	"strings"
+)
+
+// FieldError describes one missing or invalid config field.
+type FieldError struct {
+	// Section is the top-level key the field belongs to, e.g. "postgres".
+	// It is empty for top-level fields.
+	Section string
+
+	// Path is the dotted YAML path of the field, e.g. "postgres.port".
+	Path string
+
+	// Message explains what is wrong with the field.
+	Message string
+}
+
+func (f FieldError) Error() string {
+	return f.Path + ": " + f.Message
+}
+
+// ValidationError lists every problem found while reading a config.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Sections returns field errors grouped by section.
+func (v *ValidationError) Sections() map[string][]FieldError {
+	sections := make(map[string][]FieldError)
+
+	for _, err := range v.Errors {
+		sections[err.Section] = append(sections[err.Section], err)
+	}
+
+	return sections
+}
+
+func (v *ValidationError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("invalid config:")
+
+	order := make([]string, 0)
+	sections := v.Sections()
+
+	for _, err := range v.Errors {
+		if !contains(order, err.Section) {
+			order = append(order, err.Section)
+		}
+	}
+
+	for _, section := range order {
+		name := section
+		if name == "" {
+			name = "root"
+		}
+
+		sb.WriteString("\n  " + name + ":")
+
+		for _, err := range sections[section] {
+			sb.WriteString("\n    " + err.Error())
+		}
+	}
+
+	return sb.String()
+}
+
+// prefixErrors moves errors of a struct read from the subtree under key to their place in the file.
+func prefixErrors(key string, errs []FieldError) []FieldError {
+	section, _, _ := strings.Cut(key, ".")
+
+	result := make([]FieldError, 0, len(errs))
+
+	for _, err := range errs {
+		result = append(result, FieldError{
+			Section: section,
+			Path:    key + "." + err.Path,
+			Message: err.Message,
+		})
+	}
+
+	return result
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
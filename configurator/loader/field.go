@@ -0,0 +1,122 @@
+package loader
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// field is a leaf of a config struct, i.e. a value that is read from
+// one YAML scalar or one environment variable.
+type field struct {
+	path  []string
+	envs  []string
+	tag   reflect.StructTag
+	value reflect.Value
+}
+
+func (f field) section() string {
+	if len(f.path) < 2 {
+		return ""
+	}
+
+	return f.path[0]
+}
+
+func (f field) name() string {
+	return strings.Join(f.path, ".")
+}
+
+func (f field) errorf(msg string) FieldError {
+	return FieldError{
+		Section: f.section(),
+		Path:    f.name(),
+		Message: msg,
+	}
+}
+
+// fields returns the leaves of the struct v in declaration order.
+func fields(v reflect.Value) []field {
+	return collect(v, nil, "")
+}
+
+func collect(v reflect.Value, path []string, prefix string) []field {
+	result := make([]field, 0)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, ok := YamlName(sf)
+		if !ok {
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), name)
+
+		if isNested(fv.Type()) {
+			result = append(result, collect(fv, fieldPath, prefix+sf.Tag.Get("env-prefix"))...)
+			continue
+		}
+
+		envs := make([]string, 0)
+
+		if tag := sf.Tag.Get("env"); tag != "" {
+			for _, env := range strings.Split(tag, ",") {
+				envs = append(envs, prefix+env)
+			}
+		}
+
+		result = append(result, field{
+			path:  fieldPath,
+			envs:  envs,
+			tag:   sf.Tag,
+			value: fv,
+		})
+	}
+
+	return result
+}
+
+// YamlName returns the key a struct field is read from, following yaml.v3 rules.
+// ok is false for fields skipped with `yaml:"-"`.
+func YamlName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("yaml")
+
+	name, _, _ := strings.Cut(tag, ",")
+
+	if name == "-" {
+		return "", false
+	}
+
+	if name == "" {
+		name = strings.ToLower(sf.Name)
+	}
+
+	return name, true
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// isNested reports whether t is a struct whose fields are read one by one.
+func isNested(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+
+	ptr := reflect.PointerTo(t)
+
+	return !ptr.Implements(textUnmarshalerType) && !ptr.Implements(yamlUnmarshalerType)
+}
@@ -0,0 +1,146 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar is the environment variable that selects the config overlay,
+// e.g. APP_ENV=prod adds config.prod.yaml on top of config.yaml.
+const EnvVar = "APP_ENV"
+
+// OverlayPath returns the path of the env-specific file for the base config path.
+func OverlayPath(path string, env string) string {
+	ext := filepath.Ext(path)
+
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// readLayers reads the base file and merges the overlay for env into it.
+// A missing overlay is not an error: it means the env has no overrides.
+func readLayers(path string, env string) (*yaml.Node, error) {
+	root, err := readNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if env == "" {
+		return root, nil
+	}
+
+	overlay, err := readNode(OverlayPath(path, env))
+	if errors.Is(err, fs.ErrNotExist) {
+		return root, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mergeNodes(root, overlay)
+
+	return root, nil
+}
+
+func readNode(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	var node yaml.Node
+
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return &node, nil
+}
+
+// mergeNodes merges src into dst. Mappings are merged key by key,
+// any other value in src replaces the one in dst.
+func mergeNodes(dst *yaml.Node, src *yaml.Node) {
+	src = unwrapDocument(src)
+	if src == nil {
+		return
+	}
+
+	target := unwrapDocument(dst)
+	if target == nil {
+		*dst = *src
+		return
+	}
+
+	if target.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		*target = *src
+		return
+	}
+
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		found := false
+
+		for j := 0; j+1 < len(target.Content); j += 2 {
+			if target.Content[j].Value == key.Value {
+				mergeNodes(target.Content[j+1], value)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			target.Content = append(target.Content, key, value)
+		}
+	}
+}
+
+// subtree returns the node under the dotted key or nil if there is none.
+func subtree(node *yaml.Node, key string) *yaml.Node {
+	for _, name := range strings.Split(key, ".") {
+		node = unwrapDocument(node)
+
+		if node != nil && node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var next *yaml.Node
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == name {
+				next = node.Content[i+1]
+				break
+			}
+		}
+
+		node = next
+	}
+
+	return node
+}
+
+// unwrapDocument returns the content of a document node, or nil for an empty file.
+func unwrapDocument(node *yaml.Node) *yaml.Node {
+	switch node.Kind {
+	case 0:
+		return nil
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+
+		return node.Content[0]
+	default:
+		return node
+	}
+}
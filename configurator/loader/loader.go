@@ -0,0 +1,186 @@
+// Package loader reads configuration files into structs. It is shared by
+// tools.Init and the configurator package and reports every invalid field
+// at once instead of stopping at the first one.
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Options changes how Read treats the config file.
+type Options struct {
+	// Strict rejects YAML keys that don't match any field.
+	Strict bool
+
+	// AllowUnknownRoot keeps Strict from rejecting unknown top-level keys.
+	// It is used when a file is shared by several root structs, like
+	// tools.Init reading only its own sections of the application config.
+	AllowUnknownRoot bool
+
+	// Env selects the overlay file merged on top of the base one, see OverlayPath.
+	// It is usually taken from the EnvVar environment variable.
+	Env string
+}
+
+// Read fills cfg from config layers, from lowest to highest precedence:
+//
+//  1. the base file at path;
+//  2. the overlay file for opts.Env, if it exists;
+//  3. environment variables, then env-default values for fields that are still empty.
+//
+// Values from a .env file take part as environment variables once loaded with
+// godotenv.Load, which never overrides variables that are already set.
+//
+// Then string fields may hold references that are replaced with what they point to:
+// "file:///run/secrets/pg_password" is replaced with the content of the file and
+// "${NAME}" with the environment variable NAME. The paths of fields read from
+// references are returned, pass them to Dump to redact the fields.
+//
+// Finally, the rules from `validate` tags are checked, see validate for the syntax.
+//
+// YAML and JSON files are checked field by field and all problems are
+// returned together as *ValidationError.
+func Read(path string, cfg interface{}, opts Options) (Resolved, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to struct, got %T", cfg)
+	}
+
+	if !isYaml(path) {
+		return readOther(path, cfg, opts)
+	}
+
+	root, err := readLayers(path, opts.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	return load(v.Elem(), root, opts)
+}
+
+// ReadSection works like Read, but fills cfg from the subtree under key only.
+// key is a dotted path of mapping keys, like "payments" or "payments.stripe".
+// Environment variables are taken from the env tags of cfg. A missing key is
+// not an error: cfg then gets its values from environment and defaults.
+// The returned paths are relative to cfg.
+func ReadSection(path string, key string, cfg interface{}, opts Options) (Resolved, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to struct, got %T", cfg)
+	}
+
+	if !isYaml(path) {
+		return nil, fmt.Errorf("config file %s: sections are supported for YAML and JSON only", path)
+	}
+
+	root, err := readLayers(path, opts.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := load(v.Elem(), subtree(root, key), opts)
+
+	var vErr *ValidationError
+	if errors.As(err, &vErr) {
+		return nil, &ValidationError{Errors: prefixErrors(key, vErr.Errors)}
+	}
+
+	return resolved, err
+}
+
+// load decodes node into v and applies environment variables, references and
+// rules, collecting every problem on the way.
+func load(v reflect.Value, node *yaml.Node, opts Options) (Resolved, error) {
+	errs := make([]FieldError, 0)
+
+	if node != nil {
+		decodeNode(node, v, nil, opts, &errs)
+	}
+
+	envErrs := make([]FieldError, 0)
+
+	checkEnv(v, &envErrs)
+
+	if len(envErrs) != 0 {
+		// cleanenv stops at the first broken variable, so the rest can't be checked.
+		return nil, &ValidationError{Errors: append(errs, envErrs...)}
+	}
+
+	if err := cleanenv.ReadEnv(v.Addr().Interface()); err != nil {
+		return nil, err
+	}
+
+	resolved, errs := finish(v, errs)
+
+	if len(errs) != 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	return resolved, nil
+}
+
+// finish resolves references and checks the `validate` rules of a loaded config.
+// Rules are not checked for fields that already have errors.
+func finish(v reflect.Value, errs []FieldError) (Resolved, []FieldError) {
+	resolved := resolveRefs(v, &errs)
+
+	broken := make(map[string]bool, len(errs))
+	for _, err := range errs {
+		broken[err.Path] = true
+	}
+
+	ruleErrs := make([]FieldError, 0)
+
+	validate(v, &ruleErrs)
+
+	for _, err := range ruleErrs {
+		if !broken[err.Path] {
+			errs = append(errs, err)
+		}
+	}
+
+	return resolved, errs
+}
+
+// readOther reads formats other than YAML and JSON with cleanenv.
+func readOther(path string, cfg interface{}, opts Options) (Resolved, error) {
+	if err := cleanenv.ReadConfig(path, cfg); err != nil {
+		return nil, err
+	}
+
+	if opts.Env != "" {
+		overlay := OverlayPath(path, opts.Env)
+
+		_, err := os.Stat(overlay)
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			if err := cleanenv.ReadConfig(overlay, cfg); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	resolved, errs := finish(reflect.ValueOf(cfg).Elem(), nil)
+	if len(errs) != 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	return resolved, nil
+}
+
+func isYaml(path string) bool {
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,330 @@
+package loader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDb struct {
+	Host    string        `yaml:"host"    env:"TEST_DB_HOST" env-default:"localhost"`
+	Port    int           `yaml:"port"    env:"TEST_DB_PORT" env-default:"5432"`
+	Timeout time.Duration `yaml:"timeout" env:"TEST_DB_TIMEOUT"`
+	User    string        `yaml:"user"    env:"TEST_DB_USER" env-required:"true"`
+}
+
+type testCache struct {
+	Size int `yaml:"size" env:"TEST_CACHE_SIZE"`
+}
+
+type testConfig struct {
+	Db    testDb    `yaml:"db"`
+	Cache testCache `yaml:"cache"`
+}
+
+func writeConfig(t *testing.T, name string, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestRead(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+db:
+  port: 6432
+  timeout: 3s
+  user: admin
+cache:
+  size: 10
+`)
+
+	var cfg testConfig
+
+	_, err := Read(path, &cfg, Options{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, testConfig{
+		Db:    testDb{Host: "localhost", Port: 6432, Timeout: 3 * time.Second, User: "admin"},
+		Cache: testCache{Size: 10},
+	}, cfg)
+}
+
+func TestReadEnvOverride(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "db:\n  port: 6432\n  user: admin\n")
+
+	t.Setenv("TEST_DB_PORT", "7000")
+
+	var cfg testConfig
+
+	_, err := Read(path, &cfg, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, 7000, cfg.Db.Port)
+}
+
+func TestReadAllErrors(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+db:
+  port: abc
+  timeout: soon
+cache:
+  size: []
+`)
+
+	t.Setenv("TEST_DB_HOST", "db")
+	t.Setenv("TEST_CACHE_SIZE", "ten")
+
+	var cfg testConfig
+
+	_, err := Read(path, &cfg, Options{})
+
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	paths := make([]string, 0)
+	for _, fErr := range vErr.Errors {
+		paths = append(paths, fErr.Path)
+	}
+
+	assert.ElementsMatch(t, []string{"db.port", "db.timeout", "cache.size", "db.user", "cache.size"}, paths)
+	assert.Len(t, vErr.Sections()["db"], 3)
+	assert.Len(t, vErr.Sections()["cache"], 2)
+}
+
+func TestReadStrict(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+db:
+  user: admin
+  hots: db
+payments:
+  key: secret
+`)
+
+	var cfg testConfig
+
+	_, err := Read(path, &cfg, Options{})
+	assert.NoError(t, err)
+
+	_, err = Read(path, &cfg, Options{Strict: true})
+
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	assert.Equal(t, []FieldError{
+		{Section: "db", Path: "db.hots", Message: "unknown key"},
+		{Section: "", Path: "payments", Message: "unknown key"},
+	}, vErr.Errors)
+
+	_, err = Read(path, &cfg, Options{Strict: true, AllowUnknownRoot: true})
+
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	assert.Equal(t, []FieldError{
+		{Section: "db", Path: "db.hots", Message: "unknown key"},
+	}, vErr.Errors)
+}
+
+func TestReadMissingFile(t *testing.T) {
+	var cfg testConfig
+
+	_, err := Read(filepath.Join(t.TempDir(), "config.yaml"), &cfg, Options{})
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestReadOverlay(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	base := "db:\n  host: base\n  port: 6432\n  user: admin\ncache:\n  size: 10\n"
+	prod := "db:\n  host: prod\ncache:\n  size: 100\n"
+
+	if err := os.WriteFile(path, []byte(base), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(OverlayPath(path, "prod"), []byte(prod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TEST_CACHE_SIZE", "1000")
+
+	var cfg testConfig
+
+	_, err := Read(path, &cfg, Options{Env: "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, testDb{Host: "prod", Port: 6432, User: "admin"}, cfg.Db)
+	assert.Equal(t, 1000, cfg.Cache.Size)
+
+	cfg = testConfig{}
+
+	_, err = Read(path, &cfg, Options{Env: "staging"})
+	assert.NoError(t, err)
+	assert.Equal(t, "base", cfg.Db.Host)
+}
+
+func TestOverlayPath(t *testing.T) {
+	assert.Equal(t, "config/config.prod.yaml", OverlayPath("config/config.yaml", "prod"))
+	assert.Equal(t, "app.dev.json", OverlayPath("app.json", "dev"))
+}
+
+func TestDump(t *testing.T) {
+	type auth struct {
+		User     string `yaml:"user"`
+		Password string `yaml:"password" secret:"true"`
+		Token    string `yaml:"token" secret:"true"`
+	}
+
+	type root struct {
+		Auth    auth          `yaml:"auth"`
+		Timeout time.Duration `yaml:"timeout"`
+	}
+
+	data, err := Dump(&root{
+		Auth:    auth{User: "admin", Password: "qwerty"},
+		Timeout: 5 * time.Second,
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "auth:\n    user: admin\n    password: <redacted>\n    token: \"\"\ntimeout: 5s\n", string(data))
+}
+
+func TestReadReferences(t *testing.T) {
+	type auth struct {
+		User     string `yaml:"user"`
+		Password string `yaml:"password" env:"TEST_AUTH_PASSWORD"`
+		Dsn      string `yaml:"dsn"`
+	}
+
+	type root struct {
+		Auth auth `yaml:"auth"`
+	}
+
+	secret := filepath.Join(t.TempDir(), "password")
+
+	if err := os.WriteFile(secret, []byte("qwerty\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeConfig(t, "config.yaml", "auth:\n  user: ${TEST_AUTH_USER}\n  dsn: postgres://${TEST_AUTH_USER}@db\n")
+
+	t.Setenv("TEST_AUTH_USER", "admin")
+	t.Setenv("TEST_AUTH_PASSWORD", FilePrefix+secret)
+
+	var cfg root
+
+	resolved, err := Read(path, &cfg, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, auth{User: "admin", Password: "qwerty", Dsn: "postgres://admin@db"}, cfg.Auth)
+
+	data, err := Dump(&cfg, resolved)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "qwerty")
+	assert.NotContains(t, string(data), "admin")
+
+	// Reading another config of the same type doesn't change what is redacted in the first one.
+	var plain root
+
+	_, err = Read(writeConfig(t, "plain.yaml", "auth:\n  user: guest\n"), &plain, Options{})
+	assert.NoError(t, err)
+
+	data, err = Dump(&cfg, resolved)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "admin")
+}
+
+func TestReadBrokenReferences(t *testing.T) {
+	type root struct {
+		User     string `yaml:"user"`
+		Password string `yaml:"password"`
+	}
+
+	path := writeConfig(t, "config.yaml", "user: ${TEST_MISSING_USER}\npassword: file:///nonexistent/password\n")
+
+	var cfg root
+
+	_, err := Read(path, &cfg, Options{})
+
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	assert.Len(t, vErr.Errors, 2)
+}
+
+func TestReadValidate(t *testing.T) {
+	type server struct {
+		Url      string        `yaml:"url"      validate:"url"`
+		Mode     string        `yaml:"mode"     validate:"oneof=debug release"`
+		Workers  int           `yaml:"workers"  validate:"min=1,max=8"`
+		Timeout  time.Duration `yaml:"timeout"  validate:"required,min=1s,max=1m"`
+		Secret   string        `yaml:"secret"   validate:"len=16|32"`
+		Name     string        `yaml:"name"     validate:"min=3"`
+		Optional int           `yaml:"optional" validate:"min=1"`
+	}
+
+	type root struct {
+		Server server `yaml:"server"`
+	}
+
+	path := writeConfig(t, "config.yaml", `
+server:
+  url: localhost
+  mode: test
+  workers: 10
+  timeout: 2m
+  secret: short
+  name: ab
+`)
+
+	var cfg root
+
+	_, err := Read(path, &cfg, Options{})
+
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	assert.Equal(t, []FieldError{
+		{Section: "server", Path: "server.url", Message: "must be an absolute URL"},
+		{Section: "server", Path: "server.mode", Message: "must be one of: debug, release"},
+		{Section: "server", Path: "server.workers", Message: "must be at most 8"},
+		{Section: "server", Path: "server.timeout", Message: "must be at most 1m"},
+		{Section: "server", Path: "server.secret", Message: "length must be 16 or 32"},
+		{Section: "server", Path: "server.name", Message: "length must be at least 3"},
+	}, vErr.Errors)
+
+	path = writeConfig(t, "valid.yaml", `
+server:
+  url: http://localhost
+  mode: debug
+  workers: 4
+  timeout: 30s
+  secret: 0123456789abcdef
+  name: api
+`)
+
+	cfg = root{}
+
+	_, err = Read(path, &cfg, Options{})
+	assert.NoError(t, err)
+}
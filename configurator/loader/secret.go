@@ -0,0 +1,78 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// FilePrefix marks a string value that is read from a file,
+// e.g. file:///run/secrets/pg_password.
+const FilePrefix = "file://"
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Resolved holds the dotted paths of fields whose values were read from references.
+// It is returned by Read for the loaded config, so that Dump never shows them.
+type Resolved map[string]bool
+
+// resolveRefs replaces references in string fields of v with the values they point to:
+// a value starting with FilePrefix is replaced with the content of the file,
+// without trailing newlines, and every ${NAME} is replaced with the environment variable NAME.
+// It returns the paths of the replaced fields.
+func resolveRefs(v reflect.Value, errs *[]FieldError) Resolved {
+	paths := make(Resolved)
+
+	for _, f := range fields(v) {
+		if f.value.Kind() != reflect.String {
+			continue
+		}
+
+		raw := f.value.String()
+
+		value, err := resolveRef(raw)
+		if err != nil {
+			*errs = append(*errs, f.errorf(err.Error()))
+			continue
+		}
+
+		if value != raw {
+			f.value.SetString(value)
+			paths[f.name()] = true
+		}
+	}
+
+	return paths
+}
+
+func resolveRef(raw string) (string, error) {
+	if path, ok := strings.CutPrefix(raw, FilePrefix); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secret file: %w", err)
+		}
+
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	var missing []string
+
+	value := envRef.ReplaceAllStringFunc(raw, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+
+		return env
+	})
+
+	if len(missing) != 0 {
+		return "", fmt.Errorf("referenced variable %s is not set", strings.Join(missing, ", "))
+	}
+
+	return value, nil
+}
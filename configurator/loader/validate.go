@@ -0,0 +1,183 @@
+package loader
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validate checks the rules from `validate` tags of the leaves of v.
+// Rules are separated by commas:
+//
+//   - required: the value must not be empty;
+//   - min=N, max=N: bounds for numbers, durations (e.g. min=1s) and lengths of strings and slices;
+//   - len=N: exact length of a string or slice, alternatives are separated by "|", e.g. len=16|24|32;
+//   - oneof=a b c: the value must be one of the space-separated values;
+//   - url: the value must be an absolute URL.
+//
+// Except for required, rules are not checked for empty values,
+// so optional sections may be left out of the config.
+func validate(v reflect.Value, errs *[]FieldError) {
+	for _, f := range fields(v) {
+		tag := f.tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+			if name != "required" && f.value.IsZero() {
+				continue
+			}
+
+			if err := checkRule(f.value, name, param); err != nil {
+				*errs = append(*errs, f.errorf(err.Error()))
+			}
+		}
+	}
+}
+
+func checkRule(v reflect.Value, name string, param string) error {
+	switch name {
+	case "required":
+		if v.IsZero() {
+			return fmt.Errorf("value is required")
+		}
+	case "min", "max":
+		return checkBound(v, name, param)
+	case "len":
+		n, err := length(v)
+		if err != nil {
+			return err
+		}
+
+		for _, alt := range strings.Split(param, "|") {
+			if strconv.Itoa(n) == alt {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("length must be %s", strings.ReplaceAll(param, "|", " or "))
+	case "oneof":
+		values := strings.Fields(param)
+		value := fmt.Sprint(v.Interface())
+
+		for _, allowed := range values {
+			if value == allowed {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("must be one of: %s", strings.Join(values, ", "))
+	case "url":
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("rule url is not supported for %s", v.Type())
+		}
+
+		u, err := url.Parse(v.String())
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("must be an absolute URL")
+		}
+	default:
+		return fmt.Errorf("unknown validation rule %q", name)
+	}
+
+	return nil
+}
+
+func checkBound(v reflect.Value, name string, param string) error {
+	var cmp int
+
+	switch {
+	case v.Type() == durationType:
+		bound, err := time.ParseDuration(param)
+		if err != nil {
+			return fmt.Errorf("invalid rule %s=%s: %w", name, param, err)
+		}
+
+		cmp = compare(float64(v.Int()), float64(bound))
+	case isNumber(v.Kind()):
+		bound, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Errorf("invalid rule %s=%s: %w", name, param, err)
+		}
+
+		cmp = compare(number(v), bound)
+	default:
+		n, err := length(v)
+		if err != nil {
+			return err
+		}
+
+		bound, err := strconv.Atoi(param)
+		if err != nil {
+			return fmt.Errorf("invalid rule %s=%s: %w", name, param, err)
+		}
+
+		if name == "min" && n < bound {
+			return fmt.Errorf("length must be at least %d", bound)
+		}
+
+		if name == "max" && n > bound {
+			return fmt.Errorf("length must be at most %d", bound)
+		}
+
+		return nil
+	}
+
+	if name == "min" && cmp < 0 {
+		return fmt.Errorf("must be at least %s", param)
+	}
+
+	if name == "max" && cmp > 0 {
+		return fmt.Errorf("must be at most %s", param)
+	}
+
+	return nil
+}
+
+func length(v reflect.Value) (int, error) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len(), nil
+	default:
+		return 0, fmt.Errorf("length rules are not supported for %s", v.Type())
+	}
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func number(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	default:
+		return float64(v.Int())
+	}
+}
+
+func compare(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
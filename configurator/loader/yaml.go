@@ -0,0 +1,89 @@
+package loader
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeNode decodes node into v key by key, so one bad value doesn't hide the others.
+func decodeNode(node *yaml.Node, v reflect.Value, path []string, opts Options, errs *[]FieldError) {
+	switch node.Kind {
+	case 0:
+		return
+	case yaml.DocumentNode:
+		if len(node.Content) != 0 {
+			decodeNode(node.Content[0], v, path, opts, errs)
+		}
+
+		return
+	case yaml.AliasNode:
+		decodeNode(node.Alias, v, path, opts, errs)
+		return
+	}
+
+	if node.Tag == "!!null" {
+		return
+	}
+
+	if !isNested(v.Type()) {
+		if err := node.Decode(v.Addr().Interface()); err != nil {
+			*errs = append(*errs, nodeError(path, err))
+		}
+
+		return
+	}
+
+	if node.Kind != yaml.MappingNode {
+		*errs = append(*errs, nodeError(path, errors.New("expected a mapping")))
+		return
+	}
+
+	byName := make(map[string]int)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+
+		if name, ok := YamlName(t.Field(i)); ok {
+			byName[name] = i
+		}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if key.Value == "<<" && key.Tag == "!!merge" {
+			decodeNode(value, v, path, opts, errs)
+			continue
+		}
+
+		fieldPath := append(append([]string{}, path...), key.Value)
+
+		idx, ok := byName[key.Value]
+		if !ok {
+			if opts.Strict && !(len(path) == 0 && opts.AllowUnknownRoot) {
+				*errs = append(*errs, nodeError(fieldPath, errors.New("unknown key")))
+			}
+
+			continue
+		}
+
+		decodeNode(value, v.Field(idx), fieldPath, opts, errs)
+	}
+}
+
+func nodeError(path []string, err error) FieldError {
+	msg := err.Error()
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		msg = strings.Join(typeErr.Errors, "; ")
+	}
+
+	return field{path: path}.errorf(msg)
+}
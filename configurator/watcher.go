@@ -0,0 +1,257 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nikitaSstepanov/tools"
+	"github.com/nikitaSstepanov/tools/configurator/loader"
+	"github.com/nikitaSstepanov/tools/sl"
+)
+
+// Watcher reloads the config when tools.ConfigPath (or its APP_ENV overlay)
+// changes on disk or the process receives SIGHUP, and notifies subscribers
+// of the top-level sections that changed. A config that fails to load
+// is logged and dropped, the last good one stays current.
+type Watcher struct {
+	current  reflect.Value
+	resolved loader.Resolved
+	subs     map[string][]func(reflect.Value)
+	interval time.Duration
+	stamp    string
+	cancel   context.CancelFunc
+	done     chan struct{}
+	mu       sync.RWMutex
+
+	// reload serializes whole reloads, so that an older config never replaces
+	// a newer one and subscribers get changes in order.
+	reload sync.Mutex
+}
+
+// NewWatcher loads the config into cfg, which must be a pointer to struct,
+// and returns a Watcher that checks the files for changes every interval.
+// A non-positive interval disables polling, leaving SIGHUP as the only trigger.
+// cfg itself is never changed by reloads, use Config or Subscribe instead.
+func NewWatcher(cfg interface{}, interval time.Duration) (*Watcher, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to struct, got %T", cfg)
+	}
+
+	stamp := fileStamp()
+
+	resolved, err := read(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	current := reflect.New(v.Elem().Type())
+	current.Elem().Set(v.Elem())
+
+	return &Watcher{
+		current:  current,
+		resolved: resolved,
+		subs:     make(map[string][]func(reflect.Value)),
+		interval: interval,
+		stamp:    stamp,
+	}, nil
+}
+
+// Subscribe calls fn with the new value of section each time it changes.
+// section is the top-level YAML key, T must be the type of its field.
+func Subscribe[T any](w *Watcher, section string, fn func(T)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	idx, err := sectionIndex(w.current.Elem().Type(), section)
+	if err != nil {
+		return err
+	}
+
+	fieldType := w.current.Elem().Type().Field(idx).Type
+
+	if fieldType != reflect.TypeOf((*T)(nil)).Elem() {
+		return fmt.Errorf("section %s has type %s, not %T", section, fieldType, *new(T))
+	}
+
+	w.subs[section] = append(w.subs[section], func(v reflect.Value) {
+		fn(v.Interface().(T))
+	})
+
+	return nil
+}
+
+// Config returns a pointer to a copy of the current config.
+func (w *Watcher) Config() interface{} {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	cfg := reflect.New(w.current.Elem().Type())
+	cfg.Elem().Set(w.current.Elem())
+
+	return cfg.Interface()
+}
+
+// Dump returns the current config as YAML with secrets
+// and values read from references redacted.
+func (w *Watcher) Dump() ([]byte, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return loader.Dump(w.current.Interface(), w.resolved)
+}
+
+// Reload reads the config again. If it is valid, it replaces the current one
+// and subscribers of changed sections are notified; otherwise the error is returned.
+// Reloads run one at a time, so subscribers must not call Reload.
+func (w *Watcher) Reload() error {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+
+	w.mu.RLock()
+	next := reflect.New(w.current.Elem().Type())
+	w.mu.RUnlock()
+
+	resolved, err := read(next.Interface())
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+
+	prev := w.current
+	w.current = next
+	w.resolved = resolved
+
+	calls := make([]func(), 0)
+	t := next.Elem().Type()
+
+	for section, subs := range w.subs {
+		idx, _ := sectionIndex(t, section)
+
+		prevValue, nextValue := prev.Elem().Field(idx), next.Elem().Field(idx)
+
+		if reflect.DeepEqual(prevValue.Interface(), nextValue.Interface()) {
+			continue
+		}
+
+		for _, sub := range subs {
+			calls = append(calls, func() { sub(nextValue) })
+		}
+	}
+
+	w.mu.Unlock()
+
+	for _, call := range calls {
+		call()
+	}
+
+	return nil
+}
+
+// Start starts watching for file changes and SIGHUP in the background.
+// Failed reloads are logged with the logger from ctx.
+func (w *Watcher) Start(ctx context.Context) error {
+	log := sl.L(ctx)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer close(w.done)
+		defer signal.Stop(hangup)
+
+		var tick <-chan time.Time
+
+		if w.interval > 0 {
+			ticker := time.NewTicker(w.interval)
+			defer ticker.Stop()
+
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				log.Info("config: reload on SIGHUP")
+			case <-tick:
+				stamp := fileStamp()
+				if stamp == w.stamp {
+					continue
+				}
+
+				w.stamp = stamp
+			}
+
+			if err := w.Reload(); err != nil {
+				log.Error("config: reload rejected, keeping the last good config", sl.ErrAttr(err))
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops watching. It implements tools.Component together with Start.
+func (w *Watcher) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+
+	w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func sectionIndex(t reflect.Type, section string) (int, error) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name, ok := loader.YamlName(sf); ok && name == section {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("section %s not found in %s", section, t)
+}
+
+// fileStamp identifies the current state of the config files.
+func fileStamp() string {
+	paths := []string{tools.ConfigPath}
+
+	if env := os.Getenv(loader.EnvVar); env != "" {
+		paths = append(paths, loader.OverlayPath(tools.ConfigPath, env))
+	}
+
+	stamp := ""
+
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			stamp += fmt.Sprintf("%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+
+	return stamp
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/nikitaSstepanov/tools"
+	"github.com/nikitaSstepanov/tools/sl"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSection struct {
+	Size int `yaml:"size"`
+}
+
+type testConfig struct {
+	Logger sl.Config   `yaml:"logger"`
+	Cache  testSection `yaml:"cache"`
+}
+
+func useConfig(t *testing.T, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, data)
+
+	prev := tools.ConfigPath
+	tools.ConfigPath = path
+
+	t.Cleanup(func() {
+		tools.ConfigPath = prev
+	})
+
+	return path
+}
+
+func writeFile(t *testing.T, path string, data string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	path := useConfig(t, "logger:\n  level: info\ncache:\n  size: 1\n")
+
+	var cfg testConfig
+
+	w, err := NewWatcher(&cfg, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "info", cfg.Logger.Level)
+
+	levels := make([]string, 0)
+	sizes := make([]int, 0)
+
+	assert.NoError(t, Subscribe(w, "logger", func(c sl.Config) {
+		levels = append(levels, c.Level)
+	}))
+	assert.NoError(t, Subscribe(w, "cache", func(c testSection) {
+		sizes = append(sizes, c.Size)
+	}))
+
+	writeFile(t, path, "logger:\n  level: debug\ncache:\n  size: 1\n")
+
+	assert.NoError(t, w.Reload())
+	assert.Equal(t, []string{"debug"}, levels)
+	assert.Empty(t, sizes)
+
+	writeFile(t, path, "logger:\n  level: warn\ncache:\n  size: many\n")
+
+	assert.Error(t, w.Reload())
+	assert.Equal(t, []string{"debug"}, levels)
+	assert.Equal(t, "debug", w.Config().(*testConfig).Logger.Level)
+	assert.Equal(t, "info", cfg.Logger.Level)
+}
+
+func TestSubscribeErrors(t *testing.T) {
+	useConfig(t, "cache:\n  size: 1\n")
+
+	var cfg testConfig
+
+	w, err := NewWatcher(&cfg, 0)
+	assert.NoError(t, err)
+
+	assert.Error(t, Subscribe(w, "payments", func(c testSection) {}))
+	assert.Error(t, Subscribe(w, "cache", func(c sl.Config) {}))
+}
+
+func TestWatcherDump(t *testing.T) {
+	useConfig(t, "logger:\n  level: ${TEST_LOG_LEVEL}\n")
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+
+	var cfg testConfig
+
+	w, err := NewWatcher(&cfg, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "debug", cfg.Logger.Level)
+
+	data, err := w.Dump()
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "level: <redacted>")
+}
+
+func TestWatcherConcurrentReload(t *testing.T) {
+	path := useConfig(t, "cache:\n  size: 1\n")
+
+	var cfg testConfig
+
+	w, err := NewWatcher(&cfg, 0)
+	assert.NoError(t, err)
+
+	sizes := make([]int, 0)
+
+	assert.NoError(t, Subscribe(w, "cache", func(c testSection) {
+		sizes = append(sizes, c.Size)
+	}))
+
+	writeFile(t, path, "cache:\n  size: 2\n")
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, w.Reload())
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, []int{2}, sizes)
+	assert.Equal(t, 2, w.Config().(*testConfig).Cache.Size)
+}
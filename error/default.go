@@ -1,6 +1,11 @@
 package e
 
 var (
-	InternalErr = New("Something going wrong...", Internal)
-	BadInputErr = New("Bad input.", BadInput)
+	InternalErr = New("Something going wrong...", Internal).WithKey("errors.internal", nil)
+	BadInputErr = New("Bad input.", BadInput).WithKey("errors.bad_input", nil)
+	NotFoundErr = New("Not found.", NotFound).WithKey("errors.not_found", nil)
+	ConflictErr = New("Already exists.", Conflict).WithKey("errors.conflict", nil)
+
+	// ValidationErr is the error that Validation and Violations.Err derive from.
+	ValidationErr = New("Validation failed.", BadInput).WithKey("errors.validation", nil)
 )
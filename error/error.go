@@ -1,14 +1,16 @@
 package e
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/nikitaSstepanov/tools/ctx"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 // Error interface defines a custom error type that provides additional context
@@ -20,12 +22,22 @@ type Error interface {
 	// This method allows users to retrieve a human-readable description of the error.
 	GetMessage() string
 
+	// PublicMessage returns the message shown to clients in the locale. It is used by
+	// ToJson, ToProblem, ToGRPCErr and Render, while the underlying errors only go to logs.
+	// Errors with a 5xx status and underlying errors get the message of InternalErr
+	// instead of an empty message or one that contains the text of an underlying error.
+	PublicMessage(locale string) string
+
 	// GetError returns the underlying error.
-	// This method provides access to the original error that may contain more details.
+	// It is internal detail meant for logs and is never sent to clients.
 	GetError() error
 
 	GetTag(key string) interface{}
 
+	// GetStack returns the call stack recorded when the error was created,
+	// according to the stack mode. It is empty with StackNone.
+	GetStack() []Frame
+
 	// GetCode returns the status code associated with the error.
 	// The StatusType can be a custom type that represents various error codes.
 	GetCode() StatusType
@@ -44,13 +56,80 @@ type Error interface {
 
 	WithCtx(c ctx.Context) Error
 
+	// WithStack returns the error with the full call stack of the caller,
+	// regardless of the stack mode.
+	WithStack() Error
+
 	// WithCode sets a new status code for the error instance.
 	// This method allows users to update the error code dynamically.
 	WithCode(StatusType) Error
 
+	// GetKey returns the message key of the error, used to localize its message.
+	GetKey() string
+
+	// GetParams returns the params substituted into the localized message.
+	GetParams() map[string]interface{}
+
+	// WithKey sets the message key and the params for {name} placeholders of the localized message.
+	// The message of the error is used if the key has no message in the catalog.
+	WithKey(key string, params map[string]interface{}) Error
+
+	// Localize returns the message of the error in the locale, e.g. "ru" or "en-US".
+	Localize(locale string) string
+
+	// GetReason returns the machine-readable reason of the error, e.g. "EMAIL_TAKEN".
+	GetReason() string
+
+	// WithReason sets the machine-readable reason. It is sent as gRPC ErrorInfo.
+	WithReason(reason string) Error
+
+	// GetViolations returns the field violations of the error.
+	GetViolations() []Violation
+
+	// WithViolation adds a field violation. Violations are sent as gRPC BadRequest.
+	WithViolation(field string, message string) Error
+
+	// WithViolations adds field violations.
+	WithViolations(v ...Violation) Error
+
+	// GetRetryAfter returns the time after which the operation may be retried, 0 if unknown.
+	GetRetryAfter() time.Duration
+
+	// WithRetryAfter sets the time after which the operation may be retried.
+	// It is sent as gRPC RetryInfo.
+	WithRetryAfter(d time.Duration) Error
+
+	// IsRetryable reports whether the caller may retry the operation.
+	// Unless set with WithRetryable, it is true for temporary errors, errors with a retry delay
+	// and the TooManyRequests, Unavailable and Timeout statuses.
+	IsRetryable() bool
+
+	// WithRetryable marks the operation as safe or unsafe to retry.
+	WithRetryable(retryable bool) Error
+
+	// IsTemporary reports whether the error is caused by a transient condition,
+	// e.g. a dependency being down, that may go away by itself.
+	IsTemporary() bool
+
+	// WithTemporary marks the error as temporary or not.
+	WithTemporary(temporary bool) Error
+
+	// GetSeverity returns the level Log writes the error with, slog.LevelError by default.
+	GetSeverity() slog.Level
+
+	// WithSeverity sets the level Log writes the error with,
+	// e.g. slog.LevelWarn for expected errors that need no alert.
+	WithSeverity(level slog.Level) Error
+
 	// ToJson() returns erro struct with json tags.
 	ToJson() JsonError
 
+	// ToProblem returns the error as RFC 9457 problem details.
+	ToProblem() Problem
+
+	// Render writes the error as an HTTP response in the format set by SetFormat.
+	Render(w http.ResponseWriter, r *http.Request)
+
 	// ToGRPCCode converts the error's status code to a gRPC error code.
 	// This method facilitates interoperability with gRPC services by providing
 	// an appropriate error code representation.
@@ -73,6 +152,10 @@ type Error interface {
 	// ToGRPCErr converts the custom error into a standard Go error type suitable for gRPC.
 	// This method allows seamless integration with gRPC error handling mechanisms.
 	ToGRPCErr() error
+
+	// ToGRPCErrLocale is ToGRPCErr with the public message in the locale,
+	// e.g. the one LocaleFromCtx returns for the context of the call.
+	ToGRPCErrLocale(locale string) error
 }
 
 type errorStruct struct {
@@ -81,8 +164,39 @@ type errorStruct struct {
 	tags    map[string]interface{}
 	code    StatusType
 	log     *slog.Logger
+	stack   []Frame
+
+	// id identifies the error for errors.Is, ancestors are the ids
+	// of the errors it was derived from with With* methods.
+	id        uint64
+	ancestors []uint64
+
+	reason     string
+	violations []Violation
+	retryAfter time.Duration
+	retry      flag
+	temporary  bool
+	severity   slog.Level
+	key        string
+	params     map[string]interface{}
+}
+
+// Violation describes an invalid field of a request.
+type Violation struct {
+	// Field is the path of the field, e.g. "address.zip" or "items[2].count".
+	Field string `json:"field"`
+
+	// Rule is the name of the failed check, e.g. "required" or "max".
+	Rule string `json:"rule,omitempty"`
+
+	Message string `json:"message"`
+
+	// Value is the rejected value. Leave it empty for secrets.
+	Value interface{} `json:"value,omitempty"`
 }
 
+var lastID atomic.Uint64
+
 // New returns type Error with message.
 func New(msg string, status StatusType, errs ...error) Error {
 	if errs == nil {
@@ -90,18 +204,70 @@ func New(msg string, status StatusType, errs ...error) Error {
 	}
 
 	return &errorStruct{
-		message: msg,
-		errs:    errs,
-		tags:    make(map[string]interface{}),
-		code:    status,
-		log:     slog.Default(),
+		message:  msg,
+		errs:     errs,
+		tags:     make(map[string]interface{}),
+		code:     status,
+		severity: slog.LevelError,
+		stack:    callers(getStackMode()),
+		id:       lastID.Add(1),
 	}
 }
 
+// clone returns a copy of e with its own identity that descends from e, so that
+// errors.Is matches it against e and the ancestors of e, but not against the other
+// errors derived from them. Everything else, including the stack, tags, causes and the logger, is kept.
+// The stack is captured at the caller only if e has none or was created during package
+// initialization, like sentinel errors. Slices and maps are shared, so With* methods
+// replace them with changed copies instead of changing them in place.
+func (e *errorStruct) clone() *errorStruct {
+	err := *e
+	err.id = lastID.Add(1)
+
+	err.ancestors = make([]uint64, 0, len(e.ancestors)+1)
+	err.ancestors = append(err.ancestors, e.ancestors...)
+	err.ancestors = append(err.ancestors, e.id)
+
+	if len(err.stack) == 0 || isInit(err.stack) {
+		err.stack = callers(getStackMode())
+	}
+
+	return &err
+}
+
 func (e *errorStruct) GetMessage() string {
 	return e.message
 }
 
+func (e *errorStruct) PublicMessage(locale string) string {
+	msg := e.Localize(locale)
+
+	if e.code.HttpCode() < http.StatusInternalServerError || len(e.errs) == 0 {
+		return msg
+	}
+
+	if msg == "" || e.leaks(msg) {
+		return InternalErr.Localize(locale)
+	}
+
+	return msg
+}
+
+// leaks reports whether msg contains the text of an underlying error.
+func (e *errorStruct) leaks(msg string) bool {
+	for _, err := range e.errs {
+		if err == nil {
+			continue
+		}
+
+		if text := err.Error(); text != "" && strings.Contains(msg, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (e *errorStruct) GetError() error {
 	return errors.Join(e.errs...)
 }
@@ -110,17 +276,33 @@ func (e *errorStruct) GetTag(key string) interface{} {
 	return e.tags[key]
 }
 
+func (e *errorStruct) GetStack() []Frame {
+	return e.stack
+}
+
 func (e *errorStruct) GetCode() StatusType {
 	return e.code
 }
 
+// Log writes the error at its severity and counts it for WriteMetrics.
+// With SetSampling, identical errors beyond the limit are only counted.
 func (e *errorStruct) Log(msg ...string) {
-	l := e.log
+	allowed, suppressed := sampling.allow(e)
+
+	metrics.add(counterKey{code: e.code, message: e.message}, !allowed)
+
+	if suppressed != 0 {
+		e.logSuppressed(suppressed)
+	}
+
+	if !allowed {
+		return
+	}
 
-	l = l.With(e.SlErr())
+	l := e.logger().With(e.slErr(false))
 
-	for key, value := range e.tags {
-		l = l.With(key, value)
+	for _, attr := range tagAttrs(redactTags(e.tags)) {
+		l = l.With(attr)
 	}
 
 	message := ""
@@ -129,44 +311,43 @@ func (e *errorStruct) Log(msg ...string) {
 		message = strings.Join(msg, " ")
 	}
 
-	l.Error(message)
+	l.Log(context.Background(), e.severity, message)
 }
 
+// WithMessage replaces the message, dropping the message key.
 func (e *errorStruct) WithMessage(msg string) Error {
-	return New(msg, e.code, e.errs...)
-}
+	err := e.clone()
+	err.message = msg
+	err.key = ""
+	err.params = nil
 
-func (e *errorStruct) WithErr(err error) Error {
-	return New(e.message, e.code, append(e.errs, err)...)
+	return err
 }
 
-func (e *errorStruct) WithTag(key string, value interface{}) Error {
-	err := New(e.message, e.code, e.errs...).(*errorStruct)
+func (e *errorStruct) WithErr(cause error) Error {
+	err := e.clone()
+	err.errs = appendErr(e.errs, cause)
 
-	for key, value := range e.tags {
-		err.tags[key] = value
-	}
+	return err
+}
 
-	err.tags[key] = value
+func (e *errorStruct) WithTag(key string, value interface{}) Error {
+	err := e.clone()
+	err.tags = withTag(e.tags, key, value)
 
 	return err
 }
 
 func (e *errorStruct) WithCtx(c ctx.Context) Error {
-	err := New(e.message, e.code, e.errs...).(*errorStruct)
-
-	ctxErr := c.Err()
-	if ctxErr != nil {
-		err.errs = append(err.errs, c.Err())
-	}
+	err := e.clone()
 
-	for key, value := range e.tags {
-		err.tags[key] = value
+	if ctxErr := c.Err(); ctxErr != nil {
+		err.errs = appendErr(e.errs, ctxErr)
 	}
 
 	for key, value := range c.GetValues() {
 		if value.Share {
-			err.tags[key] = value.Val
+			err.tags = withTag(err.tags, key, value.Val)
 		}
 	}
 
@@ -177,44 +358,138 @@ func (e *errorStruct) WithCtx(c ctx.Context) Error {
 	return err
 }
 
-func (e *errorStruct) WithCode(status StatusType) Error {
-	return New(e.message, status, e.errs...)
+func (e *errorStruct) WithStack() Error {
+	err := e.clone()
+	err.stack = callers(StackFull)
+
+	return err
 }
 
-func (e *errorStruct) ToJson() JsonError {
-	return JsonError{
-		Error: e.message,
+// logger returns the logger bound by WithCtx, or the default one at the time of the call.
+func (e *errorStruct) logger() *slog.Logger {
+	if e.log == nil {
+		return slog.Default()
 	}
+
+	return e.log
 }
 
-// ToHttpCode convert Error to http status code.
-func (e *errorStruct) ToHttpCode() int {
-	switch e.code {
+func appendErr(errs []error, err error) []error {
+	result := make([]error, 0, len(errs)+1)
+	result = append(result, errs...)
+
+	return append(result, err)
+}
+
+func (e *errorStruct) GetKey() string {
+	return e.key
+}
+
+func (e *errorStruct) GetParams() map[string]interface{} {
+	return e.params
+}
+
+func (e *errorStruct) WithKey(key string, params map[string]interface{}) Error {
+	err := e.clone()
+	err.key = key
+	err.params = params
+
+	return err
+}
+
+func (e *errorStruct) GetReason() string {
+	return e.reason
+}
+
+func (e *errorStruct) WithReason(reason string) Error {
+	err := e.clone()
+	err.reason = reason
+
+	return err
+}
+
+func (e *errorStruct) GetViolations() []Violation {
+	return e.violations
+}
+
+func (e *errorStruct) WithViolation(field string, message string) Error {
+	return e.WithViolations(Violation{Field: field, Message: message})
+}
+
+func (e *errorStruct) WithViolations(v ...Violation) Error {
+	err := e.clone()
+	err.violations = make([]Violation, 0, len(e.violations)+len(v))
+	err.violations = append(err.violations, e.violations...)
+	err.violations = append(err.violations, v...)
+
+	return err
+}
+
+func (e *errorStruct) GetRetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+func (e *errorStruct) WithRetryAfter(d time.Duration) Error {
+	err := e.clone()
+	err.retryAfter = d
+
+	return err
+}
 
-	case Internal:
-		return http.StatusInternalServerError
+// WithCode returns a new error with the status. Unlike the other With* methods,
+// the result is a different error for errors.Is, as its kind has changed.
+func (e *errorStruct) WithCode(status StatusType) Error {
+	err := e.clone()
+	err.code = status
+	err.ancestors = nil
 
-	case NotFound:
-		return http.StatusNotFound
+	return err
+}
 
-	case BadInput:
-		return http.StatusBadRequest
+// Unwrap returns the underlying errors, so errors.Is and errors.As look through them.
+func (e *errorStruct) Unwrap() []error {
+	return e.errs
+}
+
+// Is reports whether target is e or one of the errors e was derived from with With* methods,
+// e.g. errors.Is(e.InternalErr.WithErr(err), e.InternalErr). Errors derived from the same
+// one don't match each other.
+func (e *errorStruct) Is(target error) bool {
+	t, ok := target.(*errorStruct)
+	if !ok {
+		return false
+	}
 
-	case Unauthorize:
-		return http.StatusUnauthorized
+	if t.id == e.id {
+		return true
+	}
 
-	case Forbidden:
-		return http.StatusForbidden
+	for _, id := range e.ancestors {
+		if id == t.id {
+			return true
+		}
+	}
 
-	case Conflict:
-		return http.StatusConflict
+	return false
+}
 
-	default:
-		return http.StatusInternalServerError
+// ToJson returns the error with the public message in the default locale.
+func (e *errorStruct) ToJson() JsonError {
+	return e.toJson("")
+}
 
+func (e *errorStruct) toJson(locale string) JsonError {
+	return JsonError{
+		Error:      e.PublicMessage(locale),
+		Violations: e.violations,
 	}
 }
 
+// ToHttpCode convert Error to http status code.
+func (e *errorStruct) ToHttpCode() int {
+	return e.code.HttpCode()
+}
+
 func (e *errorStruct) Error() string {
 	if e.message == "" && (e.errs == nil || (len(e.errs) == 0)) {
 		return "nil"
@@ -228,71 +503,71 @@ func (e *errorStruct) Error() string {
 	return e.message + ": " + errors.Join(e.errs...).Error()
 }
 
-func (e *errorStruct) ToGRPCErr() error {
-	return status.Error(e.ToGRPCCode(), e.message)
+// ToGRPCCode convert Error to grpc status code.
+func (e *errorStruct) ToGRPCCode() codes.Code {
+	return e.code.GRPCCode()
 }
 
-func FromGRPCErr(err error) Error {
-	stat, _ := status.FromError(err)
-
-	var code StatusType
-
-	switch stat.Code() {
-
-	case codes.Internal:
-		code = Internal
-
-	case codes.NotFound:
-		code = NotFound
-
-	case codes.InvalidArgument:
-		code = BadInput
-
-	case codes.Unauthenticated:
-		code = Unauthorize
+// SlErr returns the error message, or a group with the message, the recorded stack
+// and the tags, nested Tags as groups, if there are any.
+func (e *errorStruct) SlErr() slog.Attr {
+	return e.slErr(true)
+}
 
-	case codes.AlreadyExists:
-		code = Conflict
+// LogValue implements slog.LogValuer, so the error is logged as SlErr
+// when passed to slog directly, e.g. with slog.Any.
+func (e *errorStruct) LogValue() slog.Value {
+	return e.SlErr().Value
+}
 
-	default:
-		code = Internal
+func (e *errorStruct) slErr(withTags bool) slog.Attr {
+	attrs := make([]slog.Attr, 0, 3)
 
+	if len(e.stack) != 0 {
+		attrs = append(attrs, slog.Any("stack", e.stack))
 	}
 
-	return New(stat.Message(), code)
-}
-
-// ToGRPCCode convert Error to grpc status code.
-func (e *errorStruct) ToGRPCCode() codes.Code {
-	switch e.code {
-
-	case Internal:
-		return codes.Internal
+	if withTags && len(e.tags) != 0 {
+		attrs = append(attrs, slog.Attr{Key: "tags", Value: slog.GroupValue(tagAttrs(redactTags(e.tags))...)})
+	}
 
-	case NotFound:
-		return codes.NotFound
+	if len(attrs) == 0 {
+		return slog.String("error", e.Error())
+	}
 
-	case BadInput:
-		return codes.InvalidArgument
+	attrs = append([]slog.Attr{slog.String("msg", e.Error())}, attrs...)
 
-	case Unauthorize:
-		return codes.Unauthenticated
+	return slog.Attr{Key: "error", Value: slog.GroupValue(attrs...)}
+}
 
-	case Conflict:
-		return codes.AlreadyExists
+// HasCode reports whether err or any error in its chain is an Error with the code.
+func HasCode(err error, code StatusType) bool {
+	if err == nil {
+		return false
+	}
 
-	default:
-		return codes.Internal
+	if eErr, ok := err.(Error); ok && eErr.GetCode() == code {
+		return true
+	}
 
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, err := range u.Unwrap() {
+			if HasCode(err, code) {
+				return true
+			}
+		}
+	case interface{ Unwrap() error }:
+		return HasCode(u.Unwrap(), code)
 	}
-}
 
-func (e *errorStruct) SlErr() slog.Attr {
-	return slog.String("error", e.Error())
+	return false
 }
 
 // E creates a new custom error instance if the provided error is not nil.
-// It initializes the custom error with an empty message and associates the given error
+// Errors known to a registered translator, e.g. pg.ErrNoRows, are converted by it,
+// with the stack recorded at the caller of E.
+// Otherwise E initializes the custom error with an empty message and associates the given error
 // with an internal status code by default. If the provided error is nil, it returns nil.
 func E(err error) Error {
 	if err != nil {
@@ -300,6 +575,10 @@ func E(err error) Error {
 			return err.(Error)
 		}
 
+		if translated := translate(err); translated != nil {
+			return atCaller(translated)
+		}
+
 		return New("", Internal, err)
 	}
 
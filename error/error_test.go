@@ -2,11 +2,15 @@ package e
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nikitaSstepanov/tools/ctx"
 	"github.com/stretchr/testify/assert"
@@ -77,6 +81,9 @@ func TestGetCode(t *testing.T) {
 }
 
 func TestLog(t *testing.T) {
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
 	var buff bytes.Buffer
 
 	mockHandler := slog.NewJSONHandler(&buff, &slog.HandlerOptions{AddSource: false, Level: slog.LevelInfo})
@@ -194,6 +201,26 @@ func TestToJson(t *testing.T) {
 			err:  New("", Internal),
 			want: JsonError{Error: ""},
 		},
+		{
+			name: "Empty message with err",
+			err:  E(errors.New("dial tcp 10.0.0.5:5432: connection refused")),
+			want: JsonError{Error: "Something going wrong..."},
+		},
+		{
+			name: "Message with err text",
+			err:  New("query failed: relation users does not exist", Internal, errors.New("relation users does not exist")),
+			want: JsonError{Error: "Something going wrong..."},
+		},
+		{
+			name: "Unavailable with err text",
+			err:  New("redis: connection pool timeout", Unavailable, errors.New("redis: connection pool timeout")),
+			want: JsonError{Error: "Something going wrong..."},
+		},
+		{
+			name: "Bad input with err text",
+			err:  New("invalid email: missing @", BadInput, errors.New("missing @")),
+			want: JsonError{Error: "invalid email: missing @"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -215,6 +242,10 @@ func TestToHttpCode(t *testing.T) {
 		{Unauthorize, http.StatusUnauthorized},
 		{Forbidden, http.StatusForbidden},
 		{Conflict, http.StatusConflict},
+		{TooManyRequests, http.StatusTooManyRequests},
+		{Unavailable, http.StatusServiceUnavailable},
+		{Timeout, http.StatusGatewayTimeout},
+		{PreconditionFailed, http.StatusPreconditionFailed},
 		{99, http.StatusInternalServerError}, // Testing default case
 	}
 
@@ -268,6 +299,18 @@ func TestError(t *testing.T) {
 	}
 }
 
+func TestPublicMessage(t *testing.T) {
+	cause := errors.New("pq: password authentication failed for user \"app\"")
+	err := E(cause).WithTag("key", "value")
+
+	assert.Equal(t, "Something going wrong...", err.PublicMessage(""))
+	assert.Equal(t, "Something going wrong...", err.ToProblem().Detail)
+	assert.Equal(t, "Something going wrong...", status.Convert(err.ToGRPCErr()).Message())
+
+	assert.Contains(t, err.Error(), cause.Error())
+	assert.Contains(t, err.SlErr().String(), cause.Error())
+}
+
 func TestToGRPCErr(t *testing.T) {
 	msg := "This is a gRPC error message"
 	testErr := errors.New("some grpc error")
@@ -301,6 +344,12 @@ func TestToGRPCCode(t *testing.T) {
 		{code: NotFound, expected: codes.NotFound},
 		{code: BadInput, expected: codes.InvalidArgument},
 		{code: Conflict, expected: codes.AlreadyExists},
+		{code: Forbidden, expected: codes.PermissionDenied},
+		{code: Unauthorize, expected: codes.Unauthenticated},
+		{code: TooManyRequests, expected: codes.ResourceExhausted},
+		{code: Unavailable, expected: codes.Unavailable},
+		{code: Timeout, expected: codes.DeadlineExceeded},
+		{code: PreconditionFailed, expected: codes.FailedPrecondition},
 		{code: 99, expected: codes.Internal},
 	}
 
@@ -310,7 +359,52 @@ func TestToGRPCCode(t *testing.T) {
 	}
 }
 
+func TestStatusSymmetric(t *testing.T) {
+	for status := Internal; status <= PreconditionFailed; status++ {
+		err := New("message", status)
+
+		assert.Equal(t, status, FromHttpCode(err.ToHttpCode()), status.String())
+		assert.Equal(t, status, FromGRPCCode(err.ToGRPCCode()), status.String())
+
+		fromGrpc := FromGRPCErr(err.ToGRPCErr())
+		assert.Equal(t, status, fromGrpc.GetCode())
+		assert.Equal(t, "message", fromGrpc.GetMessage())
+	}
+
+	assert.Equal(t, Internal, FromHttpCode(http.StatusTeapot))
+	assert.Equal(t, Internal, FromGRPCCode(codes.DataLoss))
+	assert.Equal(t, "StatusType(99)", StatusType(99).String())
+}
+
+// Statuses are registered once per process, so that the tests can run with -count.
+var (
+	paymentRequired = RegisterStatus("PaymentRequired", http.StatusPaymentRequired, codes.Aborted)
+	gone            = RegisterStatus("Gone", http.StatusGone, codes.NotFound)
+)
+
+func TestRegisterStatus(t *testing.T) {
+	assert.Equal(t, "PaymentRequired", paymentRequired.String())
+	assert.NotEqual(t, paymentRequired, gone)
+
+	err := New("pay first", paymentRequired)
+
+	assert.Equal(t, http.StatusPaymentRequired, err.ToHttpCode())
+	assert.Equal(t, codes.Aborted, err.ToGRPCCode())
+	assert.Equal(t, paymentRequired, FromHttpCode(http.StatusPaymentRequired))
+	assert.Equal(t, paymentRequired, FromGRPCErr(err.ToGRPCErr()).GetCode())
+
+	assert.Equal(t, codes.NotFound, New("", gone).ToGRPCCode())
+	assert.Equal(t, gone, FromHttpCode(http.StatusGone))
+	assert.Equal(t, NotFound, FromGRPCCode(codes.NotFound))
+
+	assert.Panics(t, func() { RegisterStatus("Gone", http.StatusGone, codes.NotFound) })
+	assert.Panics(t, func() { RegisterStatus("NotFound", http.StatusNotFound, codes.NotFound) })
+}
+
 func TestSlErr(t *testing.T) {
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
 	msg := "test error for slog"
 	testErr := errors.New("some error")
 	code := Internal
@@ -343,7 +437,206 @@ func TestE(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			assert.Equal(t, tt.want, E(tt.err))
+			assert.Equal(t, withoutStack(tt.want), withoutStack(E(tt.err)))
+		})
+	}
+}
+
+func withoutStack(err Error) Error {
+	if err == nil {
+		return nil
+	}
+
+	copied := *err.(*errorStruct)
+	copied.stack = nil
+	copied.id = 0
+	copied.ancestors = nil
+
+	return &copied
+}
+
+var errSentinel Error
+
+func init() {
+	errSentinel = New("sentinel", NotFound)
+}
+
+func TestStack(t *testing.T) {
+	err := New("error", Internal)
+	_, file, line, _ := runtime.Caller(0)
+
+	assert.Equal(t, []Frame{{
+		Function: "github.com/nikitaSstepanov/tools/error.TestStack",
+		File:     file,
+		Line:     line - 1,
+	}}, err.GetStack())
+
+	err = InternalErr.WithErr(errors.New("connection refused"))
+	_, _, line, _ = runtime.Caller(0)
+
+	assert.Len(t, err.GetStack(), 1)
+	assert.Equal(t, line-1, err.GetStack()[0].Line)
+
+	full := err.WithTag("key", "value").WithStack()
+	assert.Greater(t, len(full.GetStack()), 1)
+	assert.Equal(t, "github.com/nikitaSstepanov/tools/error.TestStack", full.GetStack()[0].Function)
+	assert.Equal(t, "value", full.GetTag("key"))
+
+	assert.Len(t, errSentinel.GetStack(), 1)
+
+	sentinel := errSentinel.WithTag("key", "value")
+	assert.Equal(t, "github.com/nikitaSstepanov/tools/error.TestStack", sentinel.GetStack()[0].Function)
+
+	c := ctx.New(slog.Default())
+
+	chained := New("error", Internal).WithStack()
+	_, _, line, _ = runtime.Caller(0)
+
+	kept := chained.WithTag("key", "value").WithCtx(c).WithMessage("another error")
+	assert.Equal(t, chained.GetStack(), kept.GetStack())
+	assert.Greater(t, len(kept.GetStack()), 1)
+	assert.Equal(t, line-1, kept.GetStack()[0].Line)
+
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
+	assert.Empty(t, New("error", Internal).GetStack())
+	assert.Greater(t, len(New("error", Internal).WithStack().GetStack()), 1)
+}
+
+func TestSlErrStack(t *testing.T) {
+	var buff bytes.Buffer
+
+	err := New("error", Internal, errors.New("some error"))
+
+	log := slog.New(slog.NewJSONHandler(&buff, nil))
+	log.Error("", err.SlErr())
+
+	var record struct {
+		Error struct {
+			Msg   string  `json:"msg"`
+			Stack []Frame `json:"stack"`
+		} `json:"error"`
+	}
+
+	assert.NoError(t, json.Unmarshal(buff.Bytes(), &record))
+	assert.Equal(t, "error: some error", record.Error.Msg)
+	assert.Equal(t, err.GetStack(), record.Error.Stack)
+}
+
+type queryError struct {
+	table string
+}
+
+func (q *queryError) Error() string {
+	return "query " + q.table
+}
+
+func TestUnwrap(t *testing.T) {
+	errNoRows := errors.New("no rows in result set")
+
+	c := ctx.New(slog.Default())
+	c.AddValue("request_id", "42", true)
+
+	tests := []struct {
+		name string
+		err  Error
+	}{
+		{
+			name: "WithErr",
+			err:  InternalErr.WithErr(errNoRows),
+		},
+		{
+			name: "WithTag",
+			err:  InternalErr.WithErr(errNoRows).WithTag("key", "value"),
+		},
+		{
+			name: "WithCtx",
+			err:  InternalErr.WithErr(errNoRows).WithCtx(c),
+		},
+		{
+			name: "WithMessage",
+			err:  InternalErr.WithErr(errNoRows).WithMessage("query failed").WithStack(),
+		},
+		{
+			name: "Nested",
+			err:  New("", Internal, fmt.Errorf("repo: %w", InternalErr.WithErr(errNoRows))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.err, errNoRows)
+			assert.ErrorIs(t, tt.err, InternalErr)
+			assert.NotErrorIs(t, tt.err, BadInputErr)
+			assert.NotErrorIs(t, tt.err, New("Something going wrong...", Internal))
+			assert.True(t, HasCode(tt.err, Internal))
+			assert.False(t, HasCode(tt.err, NotFound))
 		})
 	}
+
+	err := New("", Internal, &queryError{table: "users"}).WithTag("key", "value")
+
+	var qErr *queryError
+	if assert.ErrorAs(t, err, &qErr) {
+		assert.Equal(t, "users", qErr.table)
+	}
+
+	wrapped := fmt.Errorf("service: %w", New("not found", NotFound).WithTag("key", "value"))
+
+	var eErr Error
+	if assert.ErrorAs(t, wrapped, &eErr) {
+		assert.Equal(t, NotFound, eErr.GetCode())
+	}
+
+	assert.True(t, HasCode(wrapped, NotFound))
+	assert.True(t, HasCode(errors.Join(errNoRows, BadInputErr, wrapped), NotFound))
+	assert.False(t, HasCode(errNoRows, Internal))
+
+	assert.ErrorIs(t, InternalErr, InternalErr)
+	assert.NotErrorIs(t, InternalErr.WithCode(NotFound), InternalErr)
+
+	userNotFound := NotFoundErr.WithMessage("User not found.")
+	orderNotFound := NotFoundErr.WithMessage("Order not found.")
+
+	assert.NotErrorIs(t, userNotFound, orderNotFound)
+	assert.NotErrorIs(t, orderNotFound, userNotFound)
+	assert.NotErrorIs(t, NotFoundErr.WithErr(errNoRows), userNotFound)
+	assert.NotErrorIs(t, NotFoundErr, userNotFound)
+	assert.ErrorIs(t, userNotFound.WithTag("id", 42), userNotFound)
+	assert.ErrorIs(t, userNotFound.WithTag("id", 42), NotFoundErr)
+}
+
+func TestGRPCDetails(t *testing.T) {
+	err := New("Email is taken.", Conflict).
+		WithReason("EMAIL_TAKEN").
+		WithTag("user_id", 42).
+		WithViolation("email", "already registered").
+		WithRetryAfter(3 * time.Second)
+
+	assert.Equal(t, "EMAIL_TAKEN", err.GetReason())
+	assert.Equal(t, []Violation{{Field: "email", Message: "already registered"}}, err.GetViolations())
+	assert.Equal(t, 3*time.Second, err.GetRetryAfter())
+
+	restored := FromGRPCErr(err.ToGRPCErr())
+
+	assert.Equal(t, Conflict, restored.GetCode())
+	assert.Equal(t, "Email is taken.", restored.GetMessage())
+	assert.Equal(t, "EMAIL_TAKEN", restored.GetReason())
+	assert.Equal(t, "42", restored.GetTag("user_id"))
+	assert.Equal(t, err.GetViolations(), restored.GetViolations())
+	assert.Equal(t, 3*time.Second, restored.GetRetryAfter())
+
+	plain := FromGRPCErr(New("Not found.", NotFound).WithTag("id", "7").ToGRPCErr())
+
+	assert.Equal(t, "", plain.GetReason())
+	assert.Equal(t, "7", plain.GetTag("id"))
+	assert.Empty(t, plain.GetViolations())
+	assert.Zero(t, plain.GetRetryAfter())
+
+	derived := err.WithErr(errors.New("duplicate key")).WithMessage("Conflict.")
+
+	assert.Equal(t, "EMAIL_TAKEN", derived.GetReason())
+	assert.Len(t, derived.GetViolations(), 1)
+	assert.Equal(t, 3*time.Second, derived.GetRetryAfter())
 }
@@ -0,0 +1,116 @@
+package e
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ToGRPCErr returns a gRPC status error with the code of e and its public message in the default locale.
+// The reason and tags, except LogOnly ones, are attached as ErrorInfo (tag values as strings,
+// keys of nested Tags joined by dots),
+// violations as BadRequest and the retry delay as RetryInfo,
+// which is also sent for errors marked retryable without a delay.
+func (e *errorStruct) ToGRPCErr() error {
+	return e.ToGRPCErrLocale("")
+}
+
+// ToGRPCErrLocale is ToGRPCErr with the public message in the locale.
+func (e *errorStruct) ToGRPCErrLocale(locale string) error {
+	stat := status.New(e.ToGRPCCode(), e.PublicMessage(locale))
+
+	details := e.grpcDetails()
+	if len(details) == 0 {
+		return stat.Err()
+	}
+
+	withDetails, err := stat.WithDetails(details...)
+	if err != nil {
+		return stat.Err()
+	}
+
+	return withDetails.Err()
+}
+
+func (e *errorStruct) grpcDetails() []protoadapt.MessageV1 {
+	details := make([]protoadapt.MessageV1, 0)
+
+	tags := publicTags(redactTags(e.tags))
+
+	if e.reason != "" || len(tags) != 0 {
+		info := &errdetails.ErrorInfo{
+			Reason: e.reason,
+		}
+
+		if info.Reason == "" {
+			info.Reason = e.code.String()
+		}
+
+		if len(tags) != 0 {
+			info.Metadata = make(map[string]string, len(tags))
+
+			flattenTags("", tags, info.Metadata)
+		}
+
+		details = append(details, info)
+	}
+
+	if len(e.violations) != 0 {
+		badRequest := &errdetails.BadRequest{}
+
+		for _, v := range e.violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+			})
+		}
+
+		details = append(details, badRequest)
+	}
+
+	if e.retryAfter > 0 || e.retry == yes {
+		details = append(details, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(e.retryAfter),
+		})
+	}
+
+	return details
+}
+
+// FromGRPCErr converts a gRPC status error to Error,
+// restoring the reason, tags, violations and retry delay from its details.
+func FromGRPCErr(err error) Error {
+	stat, _ := status.FromError(err)
+
+	result := New(stat.Message(), FromGRPCCode(stat.Code())).(*errorStruct)
+
+	for _, detail := range stat.Details() {
+		switch d := detail.(type) {
+
+		case *errdetails.ErrorInfo:
+			if d.Reason != result.code.String() {
+				result.reason = d.Reason
+			}
+
+			for key, value := range d.Metadata {
+				result.tags[key] = value
+			}
+
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				result.violations = append(result.violations, Violation{
+					Field:   v.Field,
+					Message: v.Description,
+				})
+			}
+
+		case *errdetails.RetryInfo:
+			result.retry = yes
+			result.retryAfter = d.RetryDelay.AsDuration()
+
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,203 @@
+package e
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// wireError is the JSON form of an Error. The code is the status name,
+// so the format does not depend on the order statuses are registered in.
+type wireError struct {
+	Message    string                 `json:"message"`
+	Code       string                 `json:"code"`
+	Tags       map[string]interface{} `json:"tags,omitempty"`
+	Causes     []string               `json:"causes,omitempty"`
+	Stack      []Frame                `json:"stack,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	Violations []Violation            `json:"violations,omitempty"`
+	RetryAfter string                 `json:"retry_after,omitempty"`
+	Retryable  *bool                  `json:"retryable,omitempty"`
+	Temporary  bool                   `json:"temporary,omitempty"`
+	Severity   string                 `json:"severity,omitempty"`
+	Key        string                 `json:"key,omitempty"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+}
+
+// wireGroup is the JSON form of a Tags or LogOnly tag value, so that FromJson
+// restores its kind and LogOnly groups stay out of client output.
+type wireGroup struct {
+	Group string                 `json:"$group"`
+	Tags  map[string]interface{} `json:"tags"`
+}
+
+const (
+	groupTags    = "tags"
+	groupLogOnly = "log_only"
+)
+
+// Marshal encodes err with its code, tags and causes for queues and job payloads.
+// Underlying errors are encoded as their text. Use FromJson to decode it.
+func Marshal(err Error) ([]byte, error) {
+	e, ok := err.(*errorStruct)
+	if !ok {
+		return nil, fmt.Errorf("unsupported error type %T", err)
+	}
+
+	w := wireError{
+		Message:    e.message,
+		Code:       e.code.String(),
+		Tags:       encodeTags(redactTags(e.tags)),
+		Stack:      e.stack,
+		Reason:     e.reason,
+		Violations: e.violations,
+		Temporary:  e.temporary,
+		Key:        e.key,
+		Params:     e.params,
+	}
+
+	for _, cause := range e.errs {
+		if cause != nil {
+			w.Causes = append(w.Causes, cause.Error())
+		}
+	}
+
+	if e.retryAfter > 0 {
+		w.RetryAfter = e.retryAfter.String()
+	}
+
+	if e.retry != unset {
+		retryable := e.retry == yes
+		w.Retryable = &retryable
+	}
+
+	if e.severity != slog.LevelError {
+		w.Severity = e.severity.String()
+	}
+
+	return json.Marshal(w)
+}
+
+// FromJson decodes an error encoded by Marshal. The status is found by its name,
+// unknown names become Internal. Underlying errors are restored as plain errors
+// with the same text; tag values have the types encoding/json decodes into, e.g. float64,
+// except Tags and LogOnly groups, which are restored as such.
+func FromJson(data []byte) (Error, error) {
+	var w wireError
+
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, err
+	}
+
+	code, _ := ParseStatus(w.Code)
+
+	causes := make([]error, 0, len(w.Causes))
+	for _, cause := range w.Causes {
+		causes = append(causes, errors.New(cause))
+	}
+
+	result := New(w.Message, code, causes...).(*errorStruct)
+
+	for key, value := range decodeTags(w.Tags) {
+		result.tags[key] = value
+	}
+
+	result.stack = w.Stack
+	result.reason = w.Reason
+	result.violations = w.Violations
+	result.temporary = w.Temporary
+	result.key = w.Key
+	result.params = w.Params
+
+	if w.RetryAfter != "" {
+		d, err := time.ParseDuration(w.RetryAfter)
+		if err != nil {
+			return nil, fmt.Errorf("retry_after: %w", err)
+		}
+
+		result.retryAfter = d
+	}
+
+	if w.Retryable != nil {
+		result.retry = no
+		if *w.Retryable {
+			result.retry = yes
+		}
+	}
+
+	if w.Severity != "" {
+		if err := result.severity.UnmarshalText([]byte(w.Severity)); err != nil {
+			return nil, fmt.Errorf("severity: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func encodeTags(tags map[string]interface{}) map[string]interface{} {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(tags))
+
+	for key, value := range tags {
+		switch group := value.(type) {
+
+		case Tags:
+			value = wireGroup{Group: groupTags, Tags: encodeTags(group)}
+
+		case LogOnly:
+			value = wireGroup{Group: groupLogOnly, Tags: encodeTags(group)}
+
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
+
+func decodeTags(tags map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(tags))
+
+	for key, value := range tags {
+		if group, ok := decodeGroup(value); ok {
+			value = group
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
+
+// decodeGroup restores a tag value encoded as wireGroup.
+func decodeGroup(value interface{}) (interface{}, bool) {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) != 2 {
+		return nil, false
+	}
+
+	kind, _ := m["$group"].(string)
+
+	tags, ok := m["tags"].(map[string]interface{})
+	if !ok && m["tags"] != nil {
+		return nil, false
+	}
+
+	switch kind {
+
+	case groupTags:
+		return Tags(decodeTags(tags)), true
+
+	case groupLogOnly:
+		return LogOnly(decodeTags(tags)), true
+
+	default:
+		return nil, false
+
+	}
+}
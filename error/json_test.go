@@ -0,0 +1,116 @@
+package e
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestJsonRoundTrip(t *testing.T) {
+	original := New("User not found.", NotFound, errors.New("no rows in result set")).
+		WithTag("user_id", 42).
+		WithTag("table", "users").
+		WithReason("USER_NOT_FOUND").
+		WithViolation("id", "unknown id").
+		WithRetryAfter(1500*time.Millisecond).
+		WithRetryable(false).
+		WithTemporary(true).
+		WithSeverity(slog.LevelWarn).
+		WithKey("errors.user_not_found", map[string]interface{}{"id": "42"}).
+		WithStack()
+
+	data, err := Marshal(original)
+	assert.NoError(t, err)
+
+	decoded, err := FromJson(data)
+	assert.NoError(t, err)
+
+	assert.Equal(t, NotFound, decoded.GetCode())
+	assert.Equal(t, "User not found.", decoded.GetMessage())
+	assert.Equal(t, "User not found.: no rows in result set", decoded.Error())
+	assert.Equal(t, float64(42), decoded.GetTag("user_id"))
+	assert.Equal(t, "users", decoded.GetTag("table"))
+	assert.Equal(t, "USER_NOT_FOUND", decoded.GetReason())
+	assert.Equal(t, original.GetViolations(), decoded.GetViolations())
+	assert.Equal(t, 1500*time.Millisecond, decoded.GetRetryAfter())
+	assert.False(t, decoded.IsRetryable())
+	assert.True(t, decoded.IsTemporary())
+	assert.Equal(t, slog.LevelWarn, decoded.GetSeverity())
+	assert.Equal(t, "errors.user_not_found", decoded.GetKey())
+	assert.Equal(t, original.GetParams(), decoded.GetParams())
+	assert.Equal(t, original.GetStack(), decoded.GetStack())
+
+	again, err := Marshal(decoded)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(data), string(again))
+}
+
+var jsonTestStatus = RegisterStatus("JsonTestStatus", http.StatusInternalServerError, codes.Internal)
+
+func TestJsonFormat(t *testing.T) {
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
+	data, err := Marshal(New("Bad input.", BadInput, errors.New("missing @")))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"message":"Bad input.","code":"BadInput","causes":["missing @"]}`, string(data))
+
+	decoded, err := FromJson([]byte(`{"message":"Gone.","code":"NoSuchStatus"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, Internal, decoded.GetCode())
+	assert.Equal(t, slog.LevelError, decoded.GetSeverity())
+
+	decoded, err = FromJson([]byte(`{"message":"Custom.","code":"JsonTestStatus"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, jsonTestStatus, decoded.GetCode())
+
+	_, err = FromJson([]byte(`{"message":"x","code":"Internal","retry_after":"soon"}`))
+	assert.Error(t, err)
+}
+
+func TestLogValue(t *testing.T) {
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
+	buff := useLogBuffer(t)
+
+	err := New("Bad input.", BadInput, errors.New("missing @"))
+
+	slog.Error("request failed", slog.Any("err", err))
+	slog.Error("request failed", slog.Any("err", err.WithTag("field", "email")))
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+
+	assert.Contains(t, lines[0], `"err":"Bad input.: missing @"`)
+	assert.Contains(t, lines[1], `"err":{"msg":"Bad input.: missing @","tags":{"field":"email"}}`)
+}
+
+func TestJsonTagGroups(t *testing.T) {
+	original := New("Already exists.", Conflict).
+		WithTag("email", "user@example.com").
+		WithTag("request", Tags{"method": "POST", "user": Tags{"id": 42}}).
+		WithTag("db", LogOnly{"constraint": "users_email_key", "table": "users"})
+
+	data, err := Marshal(original)
+	assert.NoError(t, err)
+
+	decoded, err := FromJson(data)
+	assert.NoError(t, err)
+
+	assert.Equal(t, LogOnly{"constraint": "users_email_key", "table": "users"}, decoded.GetTag("db"))
+	assert.Equal(t, Tags{"method": "POST", "user": Tags{"id": float64(42)}}, decoded.GetTag("request"))
+
+	assert.Equal(t, map[string]interface{}{
+		"email":   "user@example.com",
+		"request": Tags{"method": "POST", "user": Tags{"id": float64(42)}},
+	}, decoded.ToProblem().Extensions)
+}
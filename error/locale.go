@@ -0,0 +1,260 @@
+package e
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nikitaSstepanov/tools/ctx"
+	"gopkg.in/yaml.v3"
+)
+
+// LocaleKey is the ctx.Context value LocaleFromCtx reads the locale from.
+const LocaleKey = "locale"
+
+var catalog = &messageCatalog{
+	messages:      make(map[string]map[string]string),
+	defaultLocale: "en",
+}
+
+type messageCatalog struct {
+	messages      map[string]map[string]string
+	defaultLocale string
+	mu            sync.RWMutex
+}
+
+// SetDefaultLocale sets the locale used when no other is given or matched. It is "en" by default.
+func SetDefaultLocale(locale string) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	catalog.defaultLocale = normalizeLocale(locale)
+}
+
+// AddMessages adds message templates for the locale, replacing the ones with the same keys.
+// Templates may contain {name} placeholders, which are replaced with the params of the error.
+func AddMessages(locale string, messages map[string]string) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	locale = normalizeLocale(locale)
+
+	if catalog.messages[locale] == nil {
+		catalog.messages[locale] = make(map[string]string, len(messages))
+	}
+
+	for key, message := range messages {
+		catalog.messages[locale][key] = message
+	}
+}
+
+// LoadMessages reads message templates for the locale from a YAML or JSON file.
+// Nested keys are joined with dots:
+//
+//	errors:
+//	  user_not_found: Пользователь {id} не найден.
+//
+// defines the key "errors.user_not_found".
+func LoadMessages(locale string, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var tree map[string]interface{}
+
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	messages := make(map[string]string)
+	flatten("", tree, messages)
+
+	AddMessages(locale, messages)
+
+	return nil
+}
+
+// LoadMessagesDir loads every .yaml, .yml and .json file of dir,
+// using the file name as the locale, e.g. ru.yaml or en-US.json.
+func LoadMessagesDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		locale := strings.TrimSuffix(entry.Name(), ext)
+
+		if err := LoadMessages(locale, filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LocaleFromRequest returns the locale of the Accept-Language header
+// with the highest weight that has messages, or "" if there is none.
+func LocaleFromRequest(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	tags := make([]weighted, 0)
+
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		q := 1.0
+
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		tags = append(tags, weighted{locale: locale, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	for _, tag := range tags {
+		if locale, ok := catalog.match(normalizeLocale(tag.locale)); ok {
+			return locale
+		}
+	}
+
+	return ""
+}
+
+// LocaleFromCtx returns the locale stored in c under LocaleKey, or "" if there is none.
+func LocaleFromCtx(c ctx.Context) string {
+	value := c.GetValue(LocaleKey)
+	if value == nil {
+		return ""
+	}
+
+	locale, _ := value.Val.(string)
+
+	return locale
+}
+
+// requestLocale returns the locale of the ctx.Context of r, if it has one,
+// or the locale of its Accept-Language header.
+func requestLocale(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+
+	if c, ok := r.Context().(ctx.Context); ok {
+		if locale := LocaleFromCtx(c); locale != "" {
+			return locale
+		}
+	}
+
+	return LocaleFromRequest(r)
+}
+
+// Localize returns the message of the key of e in the locale, falling back to
+// the default locale and then to the message of e.
+func (e *errorStruct) Localize(locale string) string {
+	if e.key == "" {
+		return e.message
+	}
+
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	for _, l := range []string{normalizeLocale(locale), catalog.defaultLocale} {
+		matched, ok := catalog.match(l)
+		if !ok {
+			continue
+		}
+
+		if template, ok := catalog.messages[matched][e.key]; ok {
+			return fillTemplate(template, e.params)
+		}
+	}
+
+	return e.message
+}
+
+// match returns the locale with messages for l, trying the base language of l too.
+func (c *messageCatalog) match(l string) (string, bool) {
+	if l == "" {
+		return "", false
+	}
+
+	if _, ok := c.messages[l]; ok {
+		return l, true
+	}
+
+	base, _, _ := strings.Cut(l, "-")
+
+	if _, ok := c.messages[base]; ok {
+		return base, true
+	}
+
+	return "", false
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func fillTemplate(template string, params map[string]interface{}) string {
+	if len(params) == 0 {
+		return template
+	}
+
+	pairs := make([]string, 0, len(params)*2)
+
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+func flatten(prefix string, tree map[string]interface{}, messages map[string]string) {
+	for key, value := range tree {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+
+		case map[string]interface{}:
+			flatten(key, v, messages)
+
+		default:
+			messages[key] = fmt.Sprint(v)
+
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package e
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nikitaSstepanov/tools/ctx"
+	"github.com/stretchr/testify/assert"
+)
+
+// resetMessages empties the message catalog when the test ends.
+func resetMessages(t *testing.T) {
+	t.Cleanup(func() {
+		catalog.mu.Lock()
+		defer catalog.mu.Unlock()
+
+		catalog.messages = make(map[string]map[string]string)
+		catalog.defaultLocale = "en"
+	})
+}
+
+func TestLocalize(t *testing.T) {
+	resetMessages(t)
+
+	assert.NoError(t, LoadMessagesDir("testdata/locales"))
+
+	err := New("User not found.", NotFound).WithKey("errors.user_not_found", map[string]interface{}{"id": 42})
+
+	assert.Equal(t, "Пользователь 42 не найден.", err.Localize("ru"))
+	assert.Equal(t, "Пользователь 42 не найден.", err.Localize("ru_RU"))
+	assert.Equal(t, "User 42 not found.", err.Localize("en-US"))
+	assert.Equal(t, "User 42 not found.", err.Localize("de"))
+	assert.Equal(t, "User 42 not found.", err.ToJson().Error)
+
+	assert.Equal(t, "Что-то пошло не так...", InternalErr.Localize("ru"))
+	assert.Equal(t, "Something going wrong...", InternalErr.Localize("en"))
+	assert.Equal(t, "Bad input.", BadInputErr.Localize("ru"))
+
+	custom := err.WithMessage("Custom.")
+
+	assert.Equal(t, "", custom.GetKey())
+	assert.Equal(t, "Custom.", custom.Localize("ru"))
+
+	derived := err.WithTag("key", "value").WithErr(errors.New("no rows in result set"))
+
+	assert.Equal(t, "errors.user_not_found", derived.GetKey())
+	assert.Equal(t, map[string]interface{}{"id": 42}, derived.GetParams())
+}
+
+func TestLocaleFromRequest(t *testing.T) {
+	resetMessages(t)
+
+	AddMessages("ru", map[string]string{})
+	AddMessages("en", map[string]string{})
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "ru-RU,ru;q=0.9,en;q=0.8", want: "ru"},
+		{header: "de;q=0.9, en-GB;q=0.5, ru;q=0.7", want: "ru"},
+		{header: "fr, *", want: ""},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", tt.header)
+
+		assert.Equal(t, tt.want, LocaleFromRequest(r), tt.header)
+	}
+}
+
+func TestRenderLocalized(t *testing.T) {
+	resetMessages(t)
+
+	AddMessages("ru", map[string]string{"errors.bad_input": "Некорректные данные."})
+	AddMessages("en", map[string]string{"errors.bad_input": "Bad input."})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "ru")
+
+	rec := httptest.NewRecorder()
+	BadInputErr.Render(rec, r)
+
+	assert.JSONEq(t, `{"error":"Некорректные данные."}`, rec.Body.String())
+
+	c := ctx.New(slog.Default())
+	c.AddValue(LocaleKey, "ru", false)
+
+	assert.Equal(t, "Некорректные данные.", BadInputErr.Localize(LocaleFromCtx(c)))
+
+	// The locale of the ctx wins over Accept-Language.
+	r = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(c)
+	r.Header.Set("Accept-Language", "en")
+
+	rec = httptest.NewRecorder()
+	BadInputErr.Render(rec, r)
+
+	assert.JSONEq(t, `{"error":"Некорректные данные."}`, rec.Body.String())
+}
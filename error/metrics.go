@@ -0,0 +1,264 @@
+package e
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCounters limits the number of distinct (code, message) counters,
+	// so that messages built with fmt.Sprintf don't grow memory and label cardinality.
+	maxCounters = 1024
+
+	// OverflowMessage is the message label of errors counted after maxCounters was reached.
+	OverflowMessage = "other"
+)
+
+type counterKey struct {
+	code    StatusType
+	message string
+}
+
+type counters struct {
+	logged     map[counterKey]uint64
+	suppressed map[counterKey]uint64
+	mu         sync.Mutex
+}
+
+var metrics = &counters{
+	logged:     make(map[counterKey]uint64),
+	suppressed: make(map[counterKey]uint64),
+}
+
+func (c *counters) add(key counterKey, suppressed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.logged[key]; !ok && len(c.logged) >= maxCounters {
+		key.message = OverflowMessage
+	}
+
+	c.logged[key]++
+
+	if suppressed {
+		c.suppressed[key]++
+	}
+}
+
+// WriteMetrics writes the counters of errors passed to Error.Log, by code and message,
+// in the Prometheus text format. Once there are too many distinct messages,
+// new ones are counted under OverflowMessage.
+func WriteMetrics(w io.Writer) error {
+	metrics.mu.Lock()
+	logged := sortedCounters(metrics.logged)
+	suppressed := sortedCounters(metrics.suppressed)
+	metrics.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+
+	writeCounter(buf, "errors_logged_total", "Errors passed to Error.Log.", logged)
+	writeCounter(buf, "errors_suppressed_total", "Errors not written by Error.Log because of sampling.", suppressed)
+
+	return buf.Flush()
+}
+
+// MetricsHandler serves WriteMetrics.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		WriteMetrics(w)
+	})
+}
+
+type counter struct {
+	key   counterKey
+	value uint64
+}
+
+func sortedCounters(m map[counterKey]uint64) []counter {
+	result := make([]counter, 0, len(m))
+
+	for key, value := range m {
+		result = append(result, counter{key, value})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].key.code != result[j].key.code {
+			return result[i].key.code < result[j].key.code
+		}
+
+		return result[i].key.message < result[j].key.message
+	})
+
+	return result
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeCounter(w io.Writer, name string, help string, values []counter) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+
+	for _, c := range values {
+		fmt.Fprintf(w, "%s{code=\"%s\",message=\"%s\"} %d\n",
+			name, labelEscaper.Replace(c.key.code.String()), labelEscaper.Replace(c.key.message), c.value)
+	}
+}
+
+// maxSamples limits the number of distinct errors tracked by the sampler.
+const maxSamples = 4096
+
+type sampler struct {
+	window  time.Duration
+	burst   int
+	entries map[string]*sample
+	mu      sync.Mutex
+}
+
+type sample struct {
+	start      time.Time
+	count      int
+	suppressed int
+	last       *errorStruct
+	// timer reports the suppressed errors when the window expires.
+	timer *time.Timer
+}
+
+var sampling = &sampler{
+	entries: make(map[string]*sample),
+}
+
+// SetSampling makes Error.Log write at most burst (at least one) identical errors
+// (same code and text) per window. The others are counted and reported by one "suppressed N times" line
+// when the window expires, or by FlushSuppressed.
+// A zero window, the default, disables sampling.
+func SetSampling(window time.Duration, burst int) {
+	FlushSuppressed()
+
+	sampling.mu.Lock()
+	defer sampling.mu.Unlock()
+
+	sampling.window = window
+	sampling.burst = burst
+}
+
+// FlushSuppressed writes the "suppressed N times" lines of all errors
+// suppressed so far, e.g. on shutdown.
+func FlushSuppressed() {
+	sampling.mu.Lock()
+
+	pending := make([]*sample, 0)
+
+	for key, s := range sampling.entries {
+		if s.timer != nil {
+			s.timer.Stop()
+		}
+
+		if s.suppressed != 0 {
+			pending = append(pending, &sample{suppressed: s.suppressed, last: s.last})
+		}
+
+		delete(sampling.entries, key)
+	}
+
+	sampling.mu.Unlock()
+
+	for _, s := range pending {
+		s.last.logSuppressed(s.suppressed)
+	}
+}
+
+// allow reports whether e should be written and how many identical errors
+// were suppressed in the previous window.
+func (s *sampler) allow(e *errorStruct) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.window <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+	key := e.code.String() + "\x00" + e.Error()
+
+	entry, ok := s.entries[key]
+
+	if !ok || now.Sub(entry.start) >= s.window {
+		suppressed := 0
+		if ok {
+			suppressed = entry.suppressed
+
+			if entry.timer != nil {
+				entry.timer.Stop()
+			}
+		}
+
+		if !ok && len(s.entries) >= maxSamples {
+			s.prune(now)
+
+			if len(s.entries) >= maxSamples {
+				// Too many distinct errors to track, write the new one unsampled.
+				return true, 0
+			}
+		}
+
+		s.entries[key] = &sample{start: now, count: 1, last: e}
+
+		return true, suppressed
+	}
+
+	entry.last = e
+
+	if entry.count < s.burst {
+		entry.count++
+		return true, 0
+	}
+
+	entry.suppressed++
+
+	if entry.timer == nil {
+		entry.timer = time.AfterFunc(s.window-now.Sub(entry.start), func() {
+			s.expire(key, entry)
+		})
+	}
+
+	return false, 0
+}
+
+// expire reports the errors suppressed in the window of entry, unless
+// it was already reported by allow or FlushSuppressed.
+func (s *sampler) expire(key string, entry *sample) {
+	s.mu.Lock()
+
+	if s.entries[key] != entry {
+		s.mu.Unlock()
+		return
+	}
+
+	delete(s.entries, key)
+
+	s.mu.Unlock()
+
+	entry.last.logSuppressed(entry.suppressed)
+}
+
+// prune drops the entries of expired windows that have nothing to report.
+func (s *sampler) prune(now time.Time) {
+	for key, entry := range s.entries {
+		if entry.suppressed == 0 && now.Sub(entry.start) >= s.window {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (e *errorStruct) logSuppressed(n int) {
+	e.logger().With(e.slErr(false)).Log(context.Background(), e.severity, fmt.Sprintf("suppressed %d times", n), slog.Int("suppressed", n))
+}
@@ -0,0 +1,164 @@
+package e
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lockedBuffer is a bytes.Buffer that may be written by the sampler's timers.
+type lockedBuffer struct {
+	buff bytes.Buffer
+	mu   sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buff.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buff.String()
+}
+
+func (b *lockedBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buff.Reset()
+}
+
+func useLogBuffer(t *testing.T) *lockedBuffer {
+	var buff lockedBuffer
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buff, nil)))
+
+	t.Cleanup(func() {
+		slog.SetDefault(prev)
+	})
+
+	return &buff
+}
+
+// resetMetrics clears the counters and the sampler now and when the test ends.
+func resetMetrics(t *testing.T) {
+	reset := func() {
+		SetSampling(0, 0)
+
+		metrics.mu.Lock()
+		defer metrics.mu.Unlock()
+
+		metrics.logged = make(map[counterKey]uint64)
+		metrics.suppressed = make(map[counterKey]uint64)
+	}
+
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestWriteMetrics(t *testing.T) {
+	useLogBuffer(t)
+	resetMetrics(t)
+
+	for i := 0; i < 3; i++ {
+		New("Metrics \"test\".", NotFound).Log()
+	}
+
+	New("Metrics test.", Conflict).Log()
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, body, "# TYPE errors_logged_total counter\n")
+	assert.Contains(t, body, `errors_logged_total{code="NotFound",message="Metrics \"test\"."} 3`+"\n")
+	assert.Contains(t, body, `errors_logged_total{code="Conflict",message="Metrics test."} 1`+"\n")
+	assert.Contains(t, body, "# TYPE errors_suppressed_total counter\n")
+}
+
+func TestSampling(t *testing.T) {
+	buff := useLogBuffer(t)
+	resetMetrics(t)
+
+	SetSampling(50*time.Millisecond, 2)
+	defer SetSampling(0, 0)
+
+	cause := errors.New("connection refused")
+
+	for i := 0; i < 5; i++ {
+		InternalErr.WithErr(cause).WithMessage("Sampling test.").Log()
+	}
+
+	New("Other sampling test.", Internal).Log()
+
+	assert.Equal(t, 3, strings.Count(buff.String(), "\n"))
+
+	// The summary is written when the window expires, without another Log.
+	assert.Eventually(t, func() bool {
+		return strings.Count(buff.String(), "\n") == 4
+	}, time.Second, 5*time.Millisecond)
+
+	InternalErr.WithErr(cause).WithMessage("Sampling test.").Log()
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+
+	assert.Len(t, lines, 5)
+	assert.Contains(t, lines[3], `"msg":"suppressed 3 times"`)
+	assert.Contains(t, lines[3], `"suppressed":3`)
+
+	var metrics bytes.Buffer
+
+	assert.NoError(t, WriteMetrics(&metrics))
+	assert.Contains(t, metrics.String(), `errors_suppressed_total{code="Internal",message="Sampling test."} 3`)
+
+	buff.Reset()
+
+	for i := 0; i < 4; i++ {
+		New("Flush test.", Internal).Log()
+	}
+
+	FlushSuppressed()
+
+	assert.Contains(t, buff.String(), `"msg":"suppressed 2 times"`)
+}
+
+func TestMetricsLimits(t *testing.T) {
+	useLogBuffer(t)
+	resetMetrics(t)
+
+	SetSampling(time.Minute, 1)
+
+	for i := 0; i < maxSamples+10; i++ {
+		New(fmt.Sprintf("User %d not found.", i), NotFound).Log()
+	}
+
+	var out bytes.Buffer
+
+	assert.NoError(t, WriteMetrics(&out))
+	assert.Contains(t, out.String(), fmt.Sprintf(`errors_logged_total{code="NotFound",message="%s"} %d`, OverflowMessage, maxSamples+10-maxCounters))
+
+	metrics.mu.Lock()
+	assert.Len(t, metrics.logged, maxCounters+1)
+	metrics.mu.Unlock()
+
+	sampling.mu.Lock()
+	assert.Len(t, sampling.entries, maxSamples)
+	sampling.mu.Unlock()
+}
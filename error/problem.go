@@ -0,0 +1,182 @@
+package e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+const (
+	// LegacyFormat renders errors as JsonError, {"error": "..."}. It is the default.
+	LegacyFormat Format = iota
+
+	// ProblemFormat renders errors as RFC 9457 problem details.
+	ProblemFormat
+)
+
+const (
+	// TypeTag is the tag holding the problem type URI.
+	TypeTag = "type"
+
+	// InstanceTag is the tag holding the URI of the problem occurrence.
+	// Render uses the request path if it is not set.
+	InstanceTag = "instance"
+
+	ProblemContentType = "application/problem+json"
+)
+
+// Format is the representation used by Render.
+type Format int32
+
+var format atomic.Int32
+
+// SetFormat sets the format Render writes errors in.
+func SetFormat(f Format) {
+	format.Store(int32(f))
+}
+
+func getFormat() Format {
+	return Format(format.Load())
+}
+
+// Problem is an RFC 9457 problem details object.
+// Extensions are marshaled as top-level members next to the standard ones.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type problem Problem
+
+	var std problem
+	if err := json.Unmarshal(data, &std); err != nil {
+		return err
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, key)
+	}
+
+	*p = Problem(std)
+
+	if len(members) != 0 {
+		p.Extensions = members
+	}
+
+	return nil
+}
+
+// ToProblem returns the error as problem details, with the detail in the default locale. The type and instance are taken
+// from TypeTag and InstanceTag, the other tags, except LogOnly ones, become extension members,
+// as well as the violations, listed under "violations".
+func (e *errorStruct) ToProblem() Problem {
+	return e.toProblem("")
+}
+
+func (e *errorStruct) toProblem(locale string) Problem {
+	code := e.ToHttpCode()
+
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: e.PublicMessage(locale),
+	}
+
+	for key, value := range publicTags(redactTags(e.tags)) {
+		switch key {
+
+		case TypeTag:
+			p.Type = fmt.Sprint(value)
+
+		case InstanceTag:
+			p.Instance = fmt.Sprint(value)
+
+		default:
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]interface{})
+			}
+
+			p.Extensions[key] = value
+
+		}
+	}
+
+	if len(e.violations) != 0 {
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+
+		p.Extensions["violations"] = e.violations
+	}
+
+	return p
+}
+
+// Render writes the error to w with its HTTP status code, in the format set by SetFormat.
+// The message is localized for the locale of the ctx.Context of r, see LocaleFromCtx,
+// or else for its Accept-Language header.
+func (e *errorStruct) Render(w http.ResponseWriter, r *http.Request) {
+	var (
+		body        interface{}
+		contentType string
+	)
+
+	locale := requestLocale(r)
+
+	switch getFormat() {
+
+	case ProblemFormat:
+		p := e.toProblem(locale)
+
+		if p.Instance == "" && r != nil {
+			p.Instance = r.URL.Path
+		}
+
+		body = p
+		contentType = ProblemContentType
+
+	default:
+		body = e.toJson(locale)
+		contentType = "application/json"
+
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(e.ToHttpCode())
+
+	json.NewEncoder(w).Encode(body)
+}
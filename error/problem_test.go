@@ -0,0 +1,82 @@
+package e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToProblem(t *testing.T) {
+	err := New("User with id 42 not found.", NotFound).
+		WithTag(TypeTag, "https://example.com/problems/user-not-found").
+		WithTag(InstanceTag, "/users/42").
+		WithTag("user_id", 42)
+
+	assert.Equal(t, Problem{
+		Type:       "https://example.com/problems/user-not-found",
+		Title:      "Not Found",
+		Status:     http.StatusNotFound,
+		Detail:     "User with id 42 not found.",
+		Instance:   "/users/42",
+		Extensions: map[string]interface{}{"user_id": 42},
+	}, err.ToProblem())
+
+	assert.Equal(t, Problem{
+		Type:   "about:blank",
+		Title:  "Internal Server Error",
+		Status: http.StatusInternalServerError,
+		Detail: "Something going wrong...",
+	}, InternalErr.ToProblem())
+}
+
+func TestProblemJson(t *testing.T) {
+	p := Problem{
+		Type:       "about:blank",
+		Title:      "Conflict",
+		Status:     http.StatusConflict,
+		Extensions: map[string]interface{}{"constraint": "users_email_key", "status": "overridden"},
+	}
+
+	data, err := json.Marshal(p)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Conflict","status":409,"constraint":"users_email_key"}`, string(data))
+
+	var decoded Problem
+
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "Conflict", decoded.Title)
+	assert.Equal(t, map[string]interface{}{"constraint": "users_email_key"}, decoded.Extensions)
+}
+
+func TestRender(t *testing.T) {
+	err := New("Bad input.", BadInput).WithTag("field", "email")
+	r := httptest.NewRequest(http.MethodPost, "/users", nil)
+
+	rec := httptest.NewRecorder()
+	err.Render(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"Bad input."}`, rec.Body.String())
+
+	SetFormat(ProblemFormat)
+	defer SetFormat(LegacyFormat)
+
+	rec = httptest.NewRecorder()
+	err.Render(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "Bad input.",
+		"instance": "/users",
+		"field": "email"
+	}`, rec.Body.String())
+}
@@ -0,0 +1,66 @@
+package e
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/nikitaSstepanov/tools/ctx"
+)
+
+// Recover runs fn and returns its error as Error. A panic in fn becomes InternalErr
+// with the panic value as the underlying error and the stack of the panic. If c is
+// not nil, the panic error gets the values of c with WithCtx, which keeps the stack
+// and adds the error to the error list of c. http.ErrAbortHandler is panicked again, as net/http expects.
+func Recover(c ctx.Context, fn func() error) (err Error) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+
+		if p == http.ErrAbortHandler {
+			panic(p)
+		}
+
+		err = panicError(p)
+
+		if c != nil {
+			err = err.WithCtx(c)
+		}
+	}()
+
+	return E(fn())
+}
+
+// Go runs fn in a new goroutine with Recover. If fn fails, the error gets the values
+// of c with WithCtx, which adds it to the error list of c, and is passed to the handlers.
+// If there is neither c nor a handler, the error is logged, so it is never lost.
+func Go(c ctx.Context, fn func() error, handlers ...func(Error)) {
+	go func() {
+		err := Recover(nil, fn)
+		if err == nil {
+			return
+		}
+
+		if c != nil {
+			err = err.WithCtx(c)
+		}
+
+		for _, handler := range handlers {
+			handler(err)
+		}
+
+		if c == nil && len(handlers) == 0 {
+			err.Log("goroutine failed")
+		}
+	}()
+}
+
+func panicError(p interface{}) Error {
+	cause, ok := p.(error)
+	if !ok {
+		cause = fmt.Errorf("%v", p)
+	}
+
+	return InternalErr.WithErr(fmt.Errorf("panic: %w", cause)).WithStack()
+}
@@ -0,0 +1,124 @@
+package e
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikitaSstepanov/tools/ctx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	errNoRows := errors.New("no rows in result set")
+
+	assert.Nil(t, Recover(nil, func() error { return nil }))
+	assert.ErrorIs(t, Recover(nil, func() error { return errNoRows }), errNoRows)
+
+	err := Recover(nil, func() error {
+		panic("nil map")
+	})
+
+	assert.ErrorIs(t, err, InternalErr)
+	assert.Contains(t, err.Error(), "panic: nil map")
+	assert.Equal(t, "github.com/nikitaSstepanov/tools/error.TestRecover.func3", err.GetStack()[0].Function)
+
+	err = Recover(nil, func() error {
+		panic(errNoRows)
+	})
+
+	assert.ErrorIs(t, err, errNoRows)
+
+	c := ctx.New(slog.Default())
+	c.AddValue("job", "cleanup", true)
+
+	err = Recover(c, func() error {
+		panic("nil map")
+	})
+
+	assert.Equal(t, "cleanup", err.GetTag("job"))
+	assert.True(t, c.HasErr())
+	assert.Same(t, err, c.GetErr())
+	assert.Greater(t, len(err.GetStack()), 1)
+	assert.True(t, strings.HasPrefix(err.GetStack()[0].Function, "github.com/nikitaSstepanov/tools/error.TestRecover.func"))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		Recover(nil, func() error {
+			panic(http.ErrAbortHandler)
+		})
+	})
+}
+
+func TestGo(t *testing.T) {
+	done := make(chan Error, 1)
+
+	Go(nil, func() error {
+		panic("nil map")
+	}, func(err Error) {
+		done <- err
+	})
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, InternalErr)
+	case <-time.After(time.Second):
+		t.Fatal("handler was not called")
+	}
+
+	c := ctx.New(slog.Default())
+	c.AddValue("job", "cleanup", true)
+
+	Go(c, func() error {
+		return New("Not found.", NotFound)
+	}, func(err Error) {
+		done <- err
+	})
+
+	err := <-done
+
+	assert.Equal(t, NotFound, err.GetCode())
+	assert.Equal(t, "cleanup", err.GetTag("job"))
+	assert.True(t, c.HasErr())
+
+	Go(c, func() error {
+		panic("nil map")
+	}, func(err Error) {
+		done <- err
+	})
+
+	err = <-done
+
+	assert.ErrorIs(t, err, InternalErr)
+	assert.Equal(t, "cleanup", err.GetTag("job"))
+	assert.Greater(t, len(err.GetStack()), 1)
+	assert.True(t, strings.HasPrefix(err.GetStack()[0].Function, "github.com/nikitaSstepanov/tools/error.TestGo.func"))
+
+	logged := make(chan string, 1)
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(writerFunc(func(p []byte) (int, error) {
+		logged <- string(p)
+		return len(p), nil
+	}), nil)))
+	defer slog.SetDefault(prev)
+
+	Go(nil, func() error {
+		panic("nil map")
+	})
+
+	select {
+	case line := <-logged:
+		assert.True(t, strings.Contains(line, "goroutine failed"))
+	case <-time.After(time.Second):
+		t.Fatal("error was not logged")
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,79 @@
+package e
+
+import "log/slog"
+
+// flag is a bool that may be left unset.
+type flag int8
+
+const (
+	unset flag = iota
+	yes
+	no
+)
+
+func (e *errorStruct) IsRetryable() bool {
+	switch e.retry {
+
+	case yes:
+		return true
+
+	case no:
+		return false
+
+	}
+
+	if e.temporary || e.retryAfter > 0 {
+		return true
+	}
+
+	switch e.code {
+
+	case TooManyRequests, Unavailable, Timeout:
+		return true
+
+	default:
+		return false
+
+	}
+}
+
+func (e *errorStruct) WithRetryable(retryable bool) Error {
+	err := e.clone()
+
+	err.retry = no
+	if retryable {
+		err.retry = yes
+	}
+
+	return err
+}
+
+func (e *errorStruct) IsTemporary() bool {
+	return e.temporary
+}
+
+// Temporary is IsTemporary under the name checked by code
+// that looks for interface{ Temporary() bool }.
+func (e *errorStruct) Temporary() bool {
+	return e.temporary
+}
+
+func (e *errorStruct) WithTemporary(temporary bool) Error {
+	err := e.clone()
+
+	err.temporary = temporary
+
+	return err
+}
+
+func (e *errorStruct) GetSeverity() slog.Level {
+	return e.severity
+}
+
+func (e *errorStruct) WithSeverity(level slog.Level) Error {
+	err := e.clone()
+
+	err.severity = level
+
+	return err
+}
@@ -0,0 +1,62 @@
+package e
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  Error
+		want bool
+	}{
+		{name: "Internal", err: InternalErr, want: false},
+		{name: "Unavailable", err: New("", Unavailable), want: true},
+		{name: "Timeout", err: New("", Timeout), want: true},
+		{name: "Too many requests", err: New("", TooManyRequests), want: true},
+		{name: "Temporary", err: InternalErr.WithTemporary(true), want: true},
+		{name: "Retry after", err: New("", Conflict).WithRetryAfter(time.Second), want: true},
+		{name: "Marked", err: InternalErr.WithRetryable(true), want: true},
+		{name: "Unmarked", err: New("", Unavailable).WithTemporary(true).WithRetryable(false), want: false},
+		{name: "Derived", err: InternalErr.WithRetryable(true).WithErr(errors.New("deadlock")).WithMessage("Retry."), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.IsRetryable())
+		})
+	}
+
+	var temporary interface{ Temporary() bool }
+
+	assert.True(t, errors.As(error(InternalErr.WithTemporary(true)), &temporary))
+	assert.True(t, temporary.Temporary())
+}
+
+func TestRetryGRPC(t *testing.T) {
+	restored := FromGRPCErr(InternalErr.WithRetryable(true).ToGRPCErr())
+
+	assert.True(t, restored.IsRetryable())
+	assert.Zero(t, restored.GetRetryAfter())
+
+	assert.False(t, FromGRPCErr(InternalErr.ToGRPCErr()).IsRetryable())
+}
+
+func TestSeverity(t *testing.T) {
+	buff := useLogBuffer(t)
+
+	err := New("User not found.", NotFound)
+
+	assert.Equal(t, slog.LevelError, err.GetSeverity())
+
+	err = err.WithSeverity(slog.LevelWarn)
+	err.Log()
+
+	assert.Equal(t, slog.LevelWarn, err.WithTag("key", "value").GetSeverity())
+	assert.Contains(t, buff.String(), `"level":"WARN"`)
+}
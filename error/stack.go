@@ -0,0 +1,122 @@
+package e
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// StackNone disables capture.
+	StackNone StackMode = iota
+
+	// StackCaller records only the frame that created the error. It is the default.
+	StackCaller
+
+	// StackFull records the whole call stack.
+	StackFull
+)
+
+// StackMode sets how much of the call stack is recorded when an error is created.
+type StackMode int32
+
+// Frame is one entry of the recorded call stack.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+const maxDepth = 64
+
+var (
+	stackMode atomic.Int32
+
+	pkgPrefix = reflect.TypeOf(errorStruct{}).PkgPath() + "."
+)
+
+func init() {
+	stackMode.Store(int32(StackCaller))
+}
+
+// SetStackMode sets the stack capture mode for all errors created afterwards.
+// Use Error.WithStack to record the full stack of a single error.
+func SetStackMode(mode StackMode) {
+	stackMode.Store(int32(mode))
+}
+
+func getStackMode() StackMode {
+	return StackMode(stackMode.Load())
+}
+
+// callers returns the stack of the code that called into this package,
+// leaving out the frames of the package itself.
+func callers(mode StackMode) []Frame {
+	if mode == StackNone {
+		return nil
+	}
+
+	pcs := make([]uintptr, maxDepth)
+	n := runtime.Callers(2, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+	result := make([]Frame, 0)
+
+	inside := true
+
+	for {
+		frame, more := frames.Next()
+
+		if inside && isInternal(frame) {
+			if !more {
+				break
+			}
+
+			continue
+		}
+
+		inside = false
+
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			result = append(result, Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+
+			if mode == StackCaller {
+				break
+			}
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return result
+}
+
+func isInternal(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, pkgPrefix) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// isInit reports whether the stack was recorded during package initialization,
+// like the stack of a sentinel error declared as a package-level variable.
+func isInit(stack []Frame) bool {
+	if len(stack) == 0 {
+		return false
+	}
+
+	name := stack[0].Function
+
+	i := strings.LastIndex(name, ".init")
+	if i < 0 {
+		return false
+	}
+
+	rest := name[i+len(".init"):]
+
+	return rest == "" || rest[0] == '.'
+}
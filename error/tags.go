@@ -0,0 +1,174 @@
+package e
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// RedactedValue replaces the values of tags redacted by RedactKeys.
+const RedactedValue = "[REDACTED]"
+
+// Tags is a nested group of tags. Set as a tag value, it is logged as slog.Group:
+//
+//	err.WithTag("request", e.Tags{"method": "GET", "path": "/users"})
+type Tags map[string]interface{}
+
+// LogOnly is a group of tags written by Log and SlErr that is never sent to clients:
+// ToProblem, Render and ToGRPCErr leave it out. It suits internal details,
+// like the database schema:
+//
+//	err.WithTag("db", e.LogOnly{"table": "users", "constraint": "users_email_key"})
+type LogOnly map[string]interface{}
+
+// Redactor returns the value to render instead of the tag value,
+// e.g. RedactedValue for sensitive keys. It is called for every key,
+// including keys inside Tags, with the key itself rather than the full path.
+type Redactor func(key string, value interface{}) interface{}
+
+var redactor atomic.Pointer[Redactor]
+
+// SetRedactor sets the redactor applied to tags whenever they leave the error:
+// in Log, SlErr, ToProblem, ToGRPCErr and Marshal. GetTag returns raw values.
+// A nil redactor disables redaction.
+func SetRedactor(r Redactor) {
+	if r == nil {
+		redactor.Store(nil)
+		return
+	}
+
+	redactor.Store(&r)
+}
+
+// RedactKeys sets a redactor that replaces the values of the keys, compared
+// case-insensitively at any depth, with RedactedValue.
+func RedactKeys(keys ...string) {
+	set := make(map[string]struct{}, len(keys))
+
+	for _, key := range keys {
+		set[strings.ToLower(key)] = struct{}{}
+	}
+
+	SetRedactor(func(key string, value interface{}) interface{} {
+		if _, ok := set[strings.ToLower(key)]; ok {
+			return RedactedValue
+		}
+
+		return value
+	})
+}
+
+// withTag returns a copy of tags with the key set. Tags of an error
+// are never changed in place, so errors may be shared between goroutines.
+func withTag(tags map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(tags)+1)
+
+	for k, v := range tags {
+		result[k] = v
+	}
+
+	result[key] = value
+
+	return result
+}
+
+// redactTags returns tags with the redactor applied, or tags itself without one.
+func redactTags(tags map[string]interface{}) map[string]interface{} {
+	r := redactor.Load()
+	if r == nil || len(tags) == 0 {
+		return tags
+	}
+
+	return redactWith(*r, tags)
+}
+
+func redactWith(r Redactor, tags map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(tags))
+
+	for key, value := range tags {
+		value = r(key, value)
+
+		switch group := value.(type) {
+
+		case Tags:
+			value = Tags(redactWith(r, group))
+
+		case LogOnly:
+			value = LogOnly(redactWith(r, group))
+
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
+
+// publicTags returns tags without LogOnly groups, at any depth.
+func publicTags(tags map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(tags))
+
+	for key, value := range tags {
+		switch group := value.(type) {
+
+		case LogOnly:
+			continue
+
+		case Tags:
+			value = Tags(publicTags(group))
+
+		}
+
+		result[key] = value
+	}
+
+	return result
+}
+
+// tagAttrs returns tags as attributes sorted by key, with Tags and LogOnly as groups.
+func tagAttrs(tags map[string]interface{}) []slog.Attr {
+	keys := make([]string, 0, len(tags))
+
+	for key := range tags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+
+	for _, key := range keys {
+		switch group := tags[key].(type) {
+
+		case Tags:
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(tagAttrs(group)...)})
+
+		case LogOnly:
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(tagAttrs(group)...)})
+
+		default:
+			attrs = append(attrs, slog.Any(key, group))
+
+		}
+	}
+
+	return attrs
+}
+
+// flattenTags returns tags as strings, with keys of Tags joined by dots.
+func flattenTags(prefix string, tags map[string]interface{}, result map[string]string) {
+	for key, value := range tags {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if group, ok := value.(Tags); ok {
+			flattenTags(key, group, result)
+			continue
+		}
+
+		result[key] = fmt.Sprint(value)
+	}
+}
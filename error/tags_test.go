@@ -0,0 +1,171 @@
+package e
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/nikitaSstepanov/tools/ctx"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithPreservesTags(t *testing.T) {
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
+	var buff bytes.Buffer
+
+	c := ctx.New(slog.New(slog.NewJSONHandler(&buff, nil)))
+	cause := errors.New("connection refused")
+
+	err := New("Something going wrong...", Internal, cause).WithTag("user_id", 42).WithCtx(c)
+
+	tests := []struct {
+		name string
+		err  Error
+	}{
+		{"WithMessage", err.WithMessage("Another message.")},
+		{"WithErr", err.WithErr(errors.New("timeout"))},
+		{"WithCode", err.WithCode(Unavailable)},
+		{"WithTag", err.WithTag("order_id", 7)},
+		{"WithStack", err.WithStack()},
+		{"WithKey", err.WithKey("errors.internal", nil)},
+		{"WithReason", err.WithReason("DB_DOWN")},
+		{"WithViolation", err.WithViolation("email", "is required")},
+		{"WithRetryable", err.WithRetryable(true)},
+		{"WithTemporary", err.WithTemporary(true)},
+		{"WithSeverity", err.WithSeverity(slog.LevelWarn)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buff.Reset()
+
+			assert.Equal(t, 42, tc.err.GetTag("user_id"))
+			assert.ErrorIs(t, tc.err, cause)
+
+			tc.err.Log()
+
+			assert.Contains(t, buff.String(), `"user_id":42`)
+		})
+	}
+}
+
+func TestWithTagCopyOnWrite(t *testing.T) {
+	err := New("Something going wrong...", Internal).WithTag("a", 1)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err.WithTag("b", i).WithCode(NotFound).WithTag("c", i)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Nil(t, err.GetTag("b"))
+	assert.Nil(t, err.GetTag("c"))
+}
+
+func TestNestedTags(t *testing.T) {
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
+	buff := useLogBuffer(t)
+
+	err := New("Something going wrong...", Internal).
+		WithTag("request", Tags{"method": "GET", "user": Tags{"id": 42}})
+
+	err.Log()
+
+	assert.Contains(t, buff.String(), `"request":{"method":"GET","user":{"id":42}}`)
+
+	expected := slog.Group("error",
+		slog.String("msg", "Something going wrong..."),
+		slog.Group("tags",
+			slog.Group("request",
+				slog.String("method", "GET"),
+				slog.Group("user", slog.Int("id", 42)),
+			),
+		),
+	)
+
+	assert.True(t, expected.Equal(err.SlErr()))
+
+	stat, _ := status.FromError(err.ToGRPCErr())
+	info := stat.Details()[0].(*errdetails.ErrorInfo)
+
+	assert.Equal(t, map[string]string{"request.method": "GET", "request.user.id": "42"}, info.Metadata)
+}
+
+func TestRedactKeys(t *testing.T) {
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
+	RedactKeys("password", "Token")
+	defer SetRedactor(nil)
+
+	buff := useLogBuffer(t)
+
+	err := New("Something going wrong...", Unauthorize).
+		WithTag("login", "admin").
+		WithTag("password", "qwerty").
+		WithTag("auth", Tags{"token": "secret", "scheme": "Bearer"})
+
+	err.Log()
+
+	log := buff.String()
+
+	assert.Contains(t, log, `"password":"[REDACTED]"`)
+	assert.Contains(t, log, `"token":"[REDACTED]"`)
+	assert.Contains(t, log, `"login":"admin"`)
+	assert.NotContains(t, log, "qwerty")
+	assert.NotContains(t, log, "secret")
+
+	data, _ := Marshal(err)
+	assert.NotContains(t, string(data), "qwerty")
+
+	assert.Equal(t, RedactedValue, err.ToProblem().Extensions["password"])
+
+	stat, _ := status.FromError(err.ToGRPCErr())
+	info := stat.Details()[0].(*errdetails.ErrorInfo)
+
+	assert.Equal(t, RedactedValue, info.Metadata["auth.token"])
+
+	assert.Equal(t, "qwerty", err.GetTag("password"))
+}
+
+func TestLogOnly(t *testing.T) {
+	SetStackMode(StackNone)
+	defer SetStackMode(StackCaller)
+
+	buff := useLogBuffer(t)
+
+	err := New("Already exists.", Conflict).
+		WithTag("email", "user@example.com").
+		WithTag("db", LogOnly{"table": "users"})
+
+	err.Log()
+
+	assert.Contains(t, buff.String(), `"db":{"table":"users"}`)
+
+	assert.Equal(t, map[string]interface{}{"email": "user@example.com"}, err.ToProblem().Extensions)
+
+	stat, _ := status.FromError(err.ToGRPCErr())
+	info := stat.Details()[0].(*errdetails.ErrorInfo)
+
+	assert.Equal(t, map[string]string{"email": "user@example.com"}, info.Metadata)
+
+	stat, _ = status.FromError(New("Already exists.", Conflict).WithTag("db", LogOnly{"table": "users"}).ToGRPCErr())
+
+	assert.Empty(t, stat.Details())
+}
@@ -0,0 +1,49 @@
+package e
+
+import "sync"
+
+// Translator converts errors of a library, e.g. a database driver, to Error.
+// It returns nil for errors it does not know.
+type Translator func(err error) Error
+
+var (
+	translators  = make([]Translator, 0)
+	translatorMu sync.RWMutex
+)
+
+// RegisterTranslator adds a translator consulted by E. Translators are tried
+// in reverse registration order, so applications can override the ones
+// registered by client packages in init.
+func RegisterTranslator(t Translator) {
+	translatorMu.Lock()
+	defer translatorMu.Unlock()
+
+	translators = append(translators, t)
+}
+
+func translate(err error) Error {
+	translatorMu.RLock()
+	defer translatorMu.RUnlock()
+
+	for i := len(translators) - 1; i >= 0; i-- {
+		if result := translators[i](err); result != nil {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// atCaller returns err with the stack recorded at the code that called into this
+// package, instead of inside the translator that built err.
+func atCaller(err Error) Error {
+	e, ok := err.(*errorStruct)
+	if !ok {
+		return err
+	}
+
+	copied := *e
+	copied.stack = callers(getStackMode())
+
+	return &copied
+}
@@ -0,0 +1,34 @@
+package e
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type rateLimitError struct{}
+
+func (rateLimitError) Error() string {
+	return "rate limit exceeded"
+}
+
+func TestRegisterTranslator(t *testing.T) {
+	RegisterTranslator(func(err error) Error {
+		if errors.As(err, new(rateLimitError)) {
+			return New("Too many requests.", TooManyRequests, err)
+		}
+
+		return nil
+	})
+
+	err := E(rateLimitError{})
+
+	assert.Equal(t, TooManyRequests, err.GetCode())
+	assert.ErrorIs(t, err, rateLimitError{})
+
+	assert.Equal(t, Internal, E(errors.New("other")).GetCode())
+
+	original := New("Not found.", NotFound)
+	assert.Same(t, original, E(original))
+}
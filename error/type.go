@@ -1,7 +1,16 @@
 package e
 
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
 type JsonError struct {
-	Error string `json:"error"`
+	Error      string      `json:"error"`
+	Violations []Violation `json:"violations,omitempty"`
 }
 
 const (
@@ -11,6 +20,161 @@ const (
 	Conflict
 	Forbidden
 	Unauthorize
+	TooManyRequests
+	Unavailable
+	Timeout
+	PreconditionFailed
 )
 
 type StatusType int
+
+type statusInfo struct {
+	name     string
+	httpCode int
+	grpcCode codes.Code
+}
+
+var (
+	statuses = map[StatusType]statusInfo{
+		Internal:           {"Internal", http.StatusInternalServerError, codes.Internal},
+		NotFound:           {"NotFound", http.StatusNotFound, codes.NotFound},
+		BadInput:           {"BadInput", http.StatusBadRequest, codes.InvalidArgument},
+		Conflict:           {"Conflict", http.StatusConflict, codes.AlreadyExists},
+		Forbidden:          {"Forbidden", http.StatusForbidden, codes.PermissionDenied},
+		Unauthorize:        {"Unauthorize", http.StatusUnauthorized, codes.Unauthenticated},
+		TooManyRequests:    {"TooManyRequests", http.StatusTooManyRequests, codes.ResourceExhausted},
+		Unavailable:        {"Unavailable", http.StatusServiceUnavailable, codes.Unavailable},
+		Timeout:            {"Timeout", http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		PreconditionFailed: {"PreconditionFailed", http.StatusPreconditionFailed, codes.FailedPrecondition},
+	}
+
+	// byHttp and byGrpc are built in their initializers rather than in init,
+	// so that they are ready for statuses registered by package-level vars.
+	byHttp = reverseCodes(func(info statusInfo) int { return info.httpCode })
+	byGrpc = reverseCodes(func(info statusInfo) codes.Code { return info.grpcCode })
+
+	nextStatus = PreconditionFailed + 1
+	statusMu   sync.RWMutex
+)
+
+func reverseCodes[C comparable](code func(statusInfo) C) map[C]StatusType {
+	result := make(map[C]StatusType, len(statuses))
+
+	for status := Internal; status <= PreconditionFailed; status++ {
+		result[code(statuses[status])] = status
+	}
+
+	return result
+}
+
+// RegisterStatus adds a status with its HTTP and gRPC codes and returns it.
+// Errors with the status are converted to these codes. The reverse conversion
+// (FromHttpCode, FromGRPCErr) yields the status only for codes not taken
+// by a built-in or earlier registered status.
+// It panics if a status with the name, built-in or registered, already exists,
+// so that ParseStatus is unambiguous.
+func RegisterStatus(name string, httpCode int, grpcCode codes.Code) StatusType {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+
+	for _, info := range statuses {
+		if info.name == name {
+			panic(fmt.Sprintf("e: status %s is already registered", name))
+		}
+	}
+
+	status := nextStatus
+	nextStatus++
+
+	statuses[status] = statusInfo{
+		name:     name,
+		httpCode: httpCode,
+		grpcCode: grpcCode,
+	}
+
+	if _, ok := byHttp[httpCode]; !ok {
+		byHttp[httpCode] = status
+	}
+
+	if _, ok := byGrpc[grpcCode]; !ok {
+		byGrpc[grpcCode] = status
+	}
+
+	return status
+}
+
+func (s StatusType) String() string {
+	info, ok := lookupStatus(s)
+	if !ok {
+		return fmt.Sprintf("StatusType(%d)", int(s))
+	}
+
+	return info.name
+}
+
+// ParseStatus returns the status with the name, as returned by StatusType.String.
+func ParseStatus(name string) (StatusType, bool) {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+
+	for status, info := range statuses {
+		if info.name == name {
+			return status, true
+		}
+	}
+
+	return Internal, false
+}
+
+// HttpCode returns the HTTP status code of s, 500 for unknown statuses.
+func (s StatusType) HttpCode() int {
+	info, ok := lookupStatus(s)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	return info.httpCode
+}
+
+// GRPCCode returns the gRPC code of s, codes.Internal for unknown statuses.
+func (s StatusType) GRPCCode() codes.Code {
+	info, ok := lookupStatus(s)
+	if !ok {
+		return codes.Internal
+	}
+
+	return info.grpcCode
+}
+
+// FromHttpCode returns the status for an HTTP status code, Internal if there is none.
+func FromHttpCode(code int) StatusType {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+
+	if status, ok := byHttp[code]; ok {
+		return status
+	}
+
+	return Internal
+}
+
+// FromGRPCCode returns the status for a gRPC code, Internal if there is none.
+func FromGRPCCode(code codes.Code) StatusType {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+
+	if status, ok := byGrpc[code]; ok {
+		return status
+	}
+
+	return Internal
+}
+
+func lookupStatus(s StatusType) (statusInfo, bool) {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+
+	info, ok := statuses[s]
+
+	return info, ok
+}
@@ -0,0 +1,38 @@
+package e
+
+// Violations collects field violations while a request is validated.
+//
+//	var v e.Violations
+//
+//	if req.Email == "" {
+//		v.Add("email", "required", "Email is required.", nil)
+//	}
+//
+//	if err := v.Err(); err != nil {
+//		return err
+//	}
+type Violations []Violation
+
+// Add appends a violation of rule by field.
+func (v *Violations) Add(field string, rule string, message string, value interface{}) {
+	*v = append(*v, Violation{
+		Field:   field,
+		Rule:    rule,
+		Message: message,
+		Value:   value,
+	})
+}
+
+// Err returns a validation error with the collected violations, or nil if there are none.
+func (v Violations) Err() Error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return Validation(v...)
+}
+
+// Validation returns a BadInput error derived from ValidationErr with the violations.
+func Validation(violations ...Violation) Error {
+	return ValidationErr.WithViolations(violations...)
+}
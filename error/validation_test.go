@@ -0,0 +1,66 @@
+package e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestViolations(t *testing.T) {
+	var v Violations
+
+	assert.Nil(t, v.Err())
+
+	v.Add("email", "required", "Email is required.", nil)
+	v.Add("age", "min", "Age must be at least 18.", 16)
+
+	err := v.Err()
+
+	assert.Equal(t, BadInput, err.GetCode())
+	assert.ErrorIs(t, err, ValidationErr)
+	assert.Equal(t, []Violation(v), err.GetViolations())
+
+	assert.Equal(t, JsonError{
+		Error:      "Validation failed.",
+		Violations: []Violation(v),
+	}, err.ToJson())
+
+	data, jsonErr := json.Marshal(err.ToJson())
+
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `{
+		"error": "Validation failed.",
+		"violations": [
+			{"field": "email", "rule": "required", "message": "Email is required."},
+			{"field": "age", "rule": "min", "message": "Age must be at least 18.", "value": 16}
+		]
+	}`, string(data))
+
+	problem := err.ToProblem()
+
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, []Violation(v), problem.Extensions["violations"])
+
+	restored := FromGRPCErr(err.ToGRPCErr())
+
+	assert.Equal(t, codes.InvalidArgument, restored.ToGRPCCode())
+	assert.Equal(t, []Violation{
+		{Field: "email", Message: "Email is required."},
+		{Field: "age", Message: "Age must be at least 18."},
+	}, restored.GetViolations())
+}
+
+func TestValidation(t *testing.T) {
+	err := Validation(Violation{Field: "name", Rule: "max", Message: "Name is too long."})
+
+	assert.Equal(t, "Validation failed.", err.GetMessage())
+	assert.Len(t, err.GetViolations(), 1)
+
+	err = err.WithViolation("email", "Email is invalid.")
+
+	assert.Len(t, err.GetViolations(), 2)
+	assert.Empty(t, ValidationErr.GetViolations())
+}
@@ -0,0 +1,93 @@
+// Package grpcer contains gRPC server interceptors that turn errors
+// returned by handlers into gRPC status errors built from e.Error.
+package grpcer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nikitaSstepanov/tools/ctx"
+	e "github.com/nikitaSstepanov/tools/error"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor converts errors returned by unary handlers with Error.ToGRPCErrLocale,
+// in the locale of the ctx.Context of the call, logging them once tagged with the method.
+// A panic becomes e.InternalErr.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(c context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var resp any
+
+		err := recoverErr(c, func() (err error) {
+			resp, err = handler(c, req)
+			return err
+		})
+
+		if err != nil {
+			return nil, handle(c, err, info.FullMethod)
+		}
+
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming handlers.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		var c context.Context
+		if ss != nil {
+			c = ss.Context()
+		}
+
+		err := recoverErr(c, func() error {
+			return handler(srv, ss)
+		})
+
+		if err != nil {
+			return handle(c, err, info.FullMethod)
+		}
+
+		return nil
+	}
+}
+
+// handle logs err and converts it to a status error in the locale of c. Status errors
+// that are not e.Error, e.g. returned by a downstream client, are passed as is.
+func handle(c context.Context, err error, method string) error {
+	var eErr e.Error
+
+	if !errors.As(err, &eErr) {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+
+		eErr = e.E(err)
+	}
+
+	eErr.WithTag("method", method).Log()
+
+	locale := ""
+	if tc, ok := c.(ctx.Context); ok {
+		locale = e.LocaleFromCtx(tc)
+	}
+
+	return eErr.ToGRPCErrLocale(locale)
+}
+
+// recoverErr runs fn with e.Recover, keeping the error returned by fn as is,
+// so that status errors pass through handle unchanged.
+func recoverErr(c context.Context, fn func() error) error {
+	var err error
+
+	tc, _ := c.(ctx.Context)
+
+	if panicErr := e.Recover(tc, func() error {
+		err = fn()
+		return nil
+	}); panicErr != nil {
+		return panicErr
+	}
+
+	return err
+}
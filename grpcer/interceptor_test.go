@@ -0,0 +1,123 @@
+package grpcer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/nikitaSstepanov/tools/ctx"
+	e "github.com/nikitaSstepanov/tools/error"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.Users/Get"}
+
+	tests := []struct {
+		name    string
+		handler grpc.UnaryHandler
+		code    codes.Code
+		message string
+	}{
+		{
+			name: "Ok",
+			handler: func(ctx context.Context, req any) (any, error) {
+				return "user", nil
+			},
+			code: codes.OK,
+		},
+		{
+			name: "Error",
+			handler: func(ctx context.Context, req any) (any, error) {
+				return nil, e.New("User not found.", e.NotFound)
+			},
+			code:    codes.NotFound,
+			message: "User not found.",
+		},
+		{
+			name: "Plain error",
+			handler: func(ctx context.Context, req any) (any, error) {
+				return nil, errors.New("connection refused")
+			},
+			code:    codes.Internal,
+			message: "Something going wrong...",
+		},
+		{
+			name: "Status error",
+			handler: func(ctx context.Context, req any) (any, error) {
+				return nil, status.Error(codes.Unavailable, "downstream")
+			},
+			code:    codes.Unavailable,
+			message: "downstream",
+		},
+		{
+			name: "Panic",
+			handler: func(ctx context.Context, req any) (any, error) {
+				panic("nil map")
+			},
+			code:    codes.Internal,
+			message: "Something going wrong...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(context.Background(), nil, info, tt.handler)
+
+			stat := status.Convert(err)
+
+			assert.Equal(t, tt.code, stat.Code())
+			assert.Equal(t, tt.message, stat.Message())
+		})
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/users.Users/List"}
+
+	err := interceptor(nil, nil, info, func(srv any, stream grpc.ServerStream) error {
+		return e.New("Bad input.", e.BadInput)
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	err = interceptor(nil, nil, info, func(srv any, stream grpc.ServerStream) error {
+		panic("nil map")
+	})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	err = interceptor(nil, nil, info, func(srv any, stream grpc.ServerStream) error {
+		return nil
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestInterceptorLocale(t *testing.T) {
+	e.AddMessages("ru", map[string]string{"errors.test_not_found": "Пользователь не найден."})
+
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/users.Users/Get"}
+
+	handler := func(c context.Context, req any) (any, error) {
+		return nil, e.NotFoundErr.WithKey("errors.test_not_found", nil)
+	}
+
+	c := ctx.New(slog.Default())
+	c.AddValue(e.LocaleKey, "ru", false)
+
+	_, err := interceptor(c, nil, info, handler)
+
+	assert.Equal(t, "Пользователь не найден.", status.Convert(err).Message())
+
+	_, err = interceptor(context.Background(), nil, info, handler)
+
+	assert.Equal(t, e.NotFoundErr.GetMessage(), status.Convert(err).Message())
+}
@@ -0,0 +1,193 @@
+// Package health keeps named health checks of the components of a service
+// and runs them for liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOk       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusFail     Status = "fail"
+)
+
+// Status is the state of one check or of a whole report.
+type Status string
+
+// Check returns nil if the component is healthy.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one check.
+type Result struct {
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of all checks of a Registry.
+// Its status is "fail" if a critical check failed,
+// "degraded" if a non-critical one failed and "ok" otherwise.
+type Report struct {
+	Status Status            `json:"status"`
+	Ready  bool              `json:"ready"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type entry struct {
+	check    Check
+	timeout  time.Duration
+	critical bool
+}
+
+// Registry holds named checks.
+type Registry struct {
+	checks map[string]entry
+	ready  bool
+	mu     sync.RWMutex
+}
+
+// New returns an empty Registry that is ready.
+func New() *Registry {
+	return &Registry{
+		checks: make(map[string]entry),
+		ready:  true,
+	}
+}
+
+// Register adds a check, replacing the one with the same name.
+// Each run of the check is limited by timeout, if it is positive.
+// A failed critical check fails the whole report, a non-critical one degrades it.
+func (r *Registry) Register(name string, check Check, timeout time.Duration, critical bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks[name] = entry{
+		check:    check,
+		timeout:  timeout,
+		critical: critical,
+	}
+}
+
+// Unregister removes the check with the name.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.checks, name)
+}
+
+// SetReady marks the service as ready or not ready to receive traffic,
+// e.g. not ready while it is shutting down.
+func (r *Registry) SetReady(ready bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ready = ready
+}
+
+// Names returns the names of registered checks in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checks))
+
+	for name := range r.checks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Run runs all checks concurrently and returns the report.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+
+	checks := make(map[string]entry, len(r.checks))
+	for name, e := range r.checks {
+		checks[name] = e
+	}
+
+	ready := r.ready
+
+	r.mu.RUnlock()
+
+	report := Report{
+		Status: StatusOk,
+		Ready:  ready,
+		Checks: make(map[string]Result, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, e := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result := run(ctx, e)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+
+			if result.Status == StatusOk {
+				return
+			}
+
+			if result.Critical {
+				report.Status = StatusFail
+			} else if report.Status == StatusOk {
+				report.Status = StatusDegraded
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func run(ctx context.Context, e entry) (result Result) {
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	result.Critical = e.critical
+
+	defer func() {
+		if p := recover(); p != nil {
+			result.Status = StatusFail
+			result.Error = fmt.Sprintf("panic: %v", p)
+		}
+
+		result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	if err := e.check(ctx); err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+
+		return result
+	}
+
+	result.Status = StatusOk
+
+	return result
+}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ok(_ context.Context) error {
+	return nil
+}
+
+func fail(_ context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *Registry)
+		status   Status
+	}{
+		{
+			name:     "empty",
+			register: func(r *Registry) {},
+			status:   StatusOk,
+		},
+		{
+			name: "all ok",
+			register: func(r *Registry) {
+				r.Register("postgres", ok, time.Second, true)
+				r.Register("mail", ok, time.Second, false)
+			},
+			status: StatusOk,
+		},
+		{
+			name: "non-critical failed",
+			register: func(r *Registry) {
+				r.Register("postgres", ok, time.Second, true)
+				r.Register("mail", fail, time.Second, false)
+			},
+			status: StatusDegraded,
+		},
+		{
+			name: "critical failed",
+			register: func(r *Registry) {
+				r.Register("postgres", fail, time.Second, true)
+				r.Register("mail", fail, time.Second, false)
+			},
+			status: StatusFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			tt.register(r)
+
+			report := r.Run(context.Background())
+
+			assert.Equal(t, tt.status, report.Status)
+			assert.True(t, report.Ready)
+			assert.Len(t, report.Checks, len(r.Names()))
+		})
+	}
+}
+
+func TestRunResult(t *testing.T) {
+	r := New()
+
+	r.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, 10*time.Millisecond, true)
+
+	r.Register("panic", func(_ context.Context) error {
+		panic("boom")
+	}, 0, false)
+
+	r.Register("redis", fail, time.Second, true)
+	r.Register("redis", ok, time.Second, true)
+
+	report := r.Run(context.Background())
+
+	assert.Equal(t, []string{"panic", "redis", "slow"}, r.Names())
+
+	slow := report.Checks["slow"]
+	assert.Equal(t, StatusFail, slow.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), slow.Error)
+	assert.GreaterOrEqual(t, slow.LatencyMs, float64(10))
+
+	assert.Equal(t, Result{Status: StatusFail, Error: "panic: boom"}, withoutLatency(report.Checks["panic"]))
+	assert.Equal(t, Result{Status: StatusOk, Critical: true}, withoutLatency(report.Checks["redis"]))
+
+	r.Unregister("slow")
+	r.SetReady(false)
+
+	report = r.Run(context.Background())
+
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.False(t, report.Ready)
+}
+
+func withoutLatency(r Result) Result {
+	r.LatencyMs = 0
+	return r
+}
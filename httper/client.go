@@ -4,29 +4,54 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 	"time"
 )
 
 type ClientCfg struct {
-	Prefix  string        `yaml:"prefix" env:"HTTP_CLIENT_PREFIX" env-default:""`
-	Timeout time.Duration `yaml:"timeout" env:"HTTP_CLIENT_TIMEOUT" env-default:"5s"`
+	Prefix    string        `yaml:"prefix" env:"HTTP_CLIENT_PREFIX" env-default:"" validate:"url" env-description:"URL prepended to every request path"`
+	Timeout   time.Duration `yaml:"timeout" env:"HTTP_CLIENT_TIMEOUT" env-default:"5s" validate:"min=0s" env-description:"Timeout of a whole request"`
+	Retries   int           `yaml:"retries" env:"HTTP_CLIENT_RETRIES" env-default:"0" validate:"min=0" env-description:"Number of retries of a failed request"`
+	RetryWait time.Duration `yaml:"retryWait" env:"HTTP_CLIENT_RETRY_WAIT" env-default:"100ms" validate:"min=0s" env-description:"Wait before the first retry, doubled for each next one, unless the server sends Retry-After"`
+	MaxWait   time.Duration `yaml:"maxWait" env:"HTTP_CLIENT_MAX_WAIT" env-default:"10s" validate:"min=0s" env-description:"Longest wait between retries, also for Retry-After"`
 }
 
+// defaultMaxWait limits the wait between retries if ClientCfg.MaxWait is not set.
+const defaultMaxWait = 10 * time.Second
+
 type Client struct {
-	prefix string
-	client *http.Client
+	prefix    string
+	retries   int
+	retryWait time.Duration
+	maxWait   time.Duration
+	client    atomic.Pointer[http.Client]
 }
 
 func NewClient(cfg *ClientCfg) *Client {
-	return &Client{
-		prefix: cfg.Prefix,
-		client: &http.Client{
-			Timeout: cfg.Timeout,
-		},
+	c := &Client{
+		prefix:    cfg.Prefix,
+		retries:   cfg.Retries,
+		retryWait: cfg.RetryWait,
+		maxWait:   cfg.MaxWait,
+	}
+
+	if c.maxWait <= 0 {
+		c.maxWait = defaultMaxWait
 	}
+
+	c.SetTimeout(cfg.Timeout)
+
+	return c
+}
+
+// SetTimeout changes the timeout for new requests.
+// Requests that are already running keep the old one.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.client.Store(&http.Client{
+		Timeout: timeout,
+	})
 }
 
 func (c *Client) Get(url string) (*Resp, error) {
@@ -34,13 +59,12 @@ func (c *Client) Get(url string) (*Resp, error) {
 		url = c.prefix + url
 	}
 
-	resp, err := c.client.Get(url)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	resp, body, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -72,15 +96,14 @@ func (c *Client) PostWithJson(url string, data interface{}) (*Resp, error) {
 		return nil, err
 	}
 
-	reader := bytes.NewReader(body)
-
-	resp, err := c.client.Post(url, string(JsonType), reader)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err = io.ReadAll(resp.Body)
+	req.Header.Set("Content-Type", string(JsonType))
+
+	resp, body, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -112,13 +135,7 @@ func (c *Client) Do(req *Req) (*Resp, error) {
 		req.URL = newUrl
 	}
 
-	resp, err := c.client.Do(req.Request)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	resp, body, err := c.send(req.Request)
 	if err != nil {
 		return nil, err
 	}
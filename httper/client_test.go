@@ -0,0 +1,148 @@
+package httper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		header   string
+		post     bool
+		retries  int
+		calls    int32
+		code     int
+	}{
+		{
+			name:     "No retries",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			retries:  0,
+			calls:    1,
+			code:     http.StatusServiceUnavailable,
+		},
+		{
+			name:     "Unavailable",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			retries:  3,
+			calls:    3,
+			code:     http.StatusOK,
+		},
+		{
+			name:     "Retries exhausted",
+			statuses: []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusOK},
+			retries:  1,
+			calls:    2,
+			code:     http.StatusGatewayTimeout,
+		},
+		{
+			name:     "Retry-After clamped",
+			statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			header:   "3600",
+			retries:  1,
+			calls:    2,
+			code:     http.StatusOK,
+		},
+		{
+			name:     "Not retryable",
+			statuses: []int{http.StatusInternalServerError, http.StatusOK},
+			retries:  3,
+			calls:    1,
+			code:     http.StatusInternalServerError,
+		},
+		{
+			name:     "Post conflict",
+			statuses: []int{http.StatusConflict, http.StatusCreated},
+			header:   "0",
+			post:     true,
+			retries:  3,
+			calls:    1,
+			code:     http.StatusConflict,
+		},
+		{
+			name:     "Post with too many requests",
+			statuses: []int{http.StatusTooManyRequests, http.StatusCreated},
+			post:     true,
+			retries:  3,
+			calls:    2,
+			code:     http.StatusCreated,
+		},
+		{
+			name:     "Post with gateway timeout",
+			statuses: []int{http.StatusGatewayTimeout, http.StatusCreated},
+			post:     true,
+			retries:  3,
+			calls:    1,
+			code:     http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+
+				body, _ := io.ReadAll(r.Body)
+				if tt.post {
+					assert.JSONEq(t, `{"name":"test"}`, string(body))
+				}
+
+				if tt.header != "" {
+					w.Header().Set("Retry-After", tt.header)
+				}
+
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			client := NewClient(&ClientCfg{
+				Prefix:    server.URL,
+				Timeout:   time.Second,
+				Retries:   tt.retries,
+				RetryWait: time.Millisecond,
+				MaxWait:   10 * time.Millisecond,
+			})
+
+			var (
+				resp *Resp
+				err  error
+			)
+
+			if tt.post {
+				resp, err = client.PostWithJson("/users", map[string]string{"name": "test"})
+			} else {
+				resp, err = client.Get("/users")
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.code, resp.StatusCode)
+			assert.Equal(t, tt.calls, calls.Load())
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 100*time.Millisecond, backoff(100*time.Millisecond, 0))
+	assert.Equal(t, 400*time.Millisecond, backoff(100*time.Millisecond, 2))
+	assert.Positive(t, backoff(100*time.Millisecond, 1000))
+	assert.Zero(t, backoff(0, 1000))
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, retryAfter("3"))
+	assert.Positive(t, retryAfter("99999999999999999"))
+	assert.Zero(t, retryAfter(""))
+	assert.Zero(t, retryAfter("soon"))
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, float64(time.Minute), float64(retryAfter(date)), float64(2*time.Second))
+}
@@ -0,0 +1,34 @@
+package httper
+
+import (
+	"net/http"
+
+	"github.com/nikitaSstepanov/tools/ctx"
+	e "github.com/nikitaSstepanov/tools/error"
+)
+
+// HandlerFunc is a handler that returns an error instead of writing it.
+// ServeHTTP logs the returned error once, tagged with the request method and path,
+// and renders it with Error.Render. A panic becomes e.InternalErr.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) e.Error
+
+// Handle adapts fn to http.Handler.
+func Handle(fn func(w http.ResponseWriter, r *http.Request) e.Error) http.Handler {
+	return HandlerFunc(fn)
+}
+
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, _ := r.Context().(ctx.Context)
+
+	err := e.Recover(c, func() error {
+		return h(w, r)
+	})
+
+	if err == nil {
+		return
+	}
+
+	err.WithTag("method", r.Method).WithTag("path", r.URL.Path).Log()
+
+	err.Render(w, r)
+}
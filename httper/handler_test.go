@@ -0,0 +1,90 @@
+package httper
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	e "github.com/nikitaSstepanov/tools/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandle(t *testing.T) {
+	var buff bytes.Buffer
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buff, nil)))
+	defer slog.SetDefault(prev)
+
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, r *http.Request) e.Error
+		code    int
+		body    string
+		logs    int
+	}{
+		{
+			name: "Ok",
+			handler: func(w http.ResponseWriter, r *http.Request) e.Error {
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			},
+			code: http.StatusNoContent,
+			body: "",
+		},
+		{
+			name: "Error",
+			handler: func(w http.ResponseWriter, r *http.Request) e.Error {
+				return e.New("User not found.", e.NotFound)
+			},
+			code: http.StatusNotFound,
+			body: `{"error":"User not found."}`,
+			logs: 1,
+		},
+		{
+			name: "Internal",
+			handler: func(w http.ResponseWriter, r *http.Request) e.Error {
+				return e.E(errors.New("connection refused"))
+			},
+			code: http.StatusInternalServerError,
+			body: `{"error":"Something going wrong..."}`,
+			logs: 1,
+		},
+		{
+			name: "Panic",
+			handler: func(w http.ResponseWriter, r *http.Request) e.Error {
+				panic("nil map")
+			},
+			code: http.StatusInternalServerError,
+			body: `{"error":"Something going wrong..."}`,
+			logs: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buff.Reset()
+
+			rec := httptest.NewRecorder()
+			Handle(tt.handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+			assert.Equal(t, tt.code, rec.Code)
+
+			if tt.body != "" {
+				assert.JSONEq(t, tt.body, rec.Body.String())
+			}
+
+			assert.Equal(t, tt.logs, strings.Count(buff.String(), "\n"))
+
+			if tt.logs != 0 {
+				assert.Contains(t, buff.String(), `"method":"GET","path":"/users/42"`)
+			}
+		})
+	}
+
+	assert.Contains(t, buff.String(), "panic: nil map")
+}
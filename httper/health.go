@@ -0,0 +1,58 @@
+package httper
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nikitaSstepanov/tools/health"
+	"github.com/nikitaSstepanov/tools/sl"
+)
+
+// HealthHandler serves the liveness probe on /healthz and the readiness probe on /readyz.
+// /healthz only shows that the process serves requests, so that an outage of a dependency
+// doesn't make the orchestrator restart every instance. /readyz runs the checks of reg
+// and responds 503 if a critical one fails or the registry is not ready.
+// Check errors are logged, not sent, as the endpoints have no authentication.
+func HealthHandler(reg *health.Registry) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, struct {
+			Status health.Status `json:"status"`
+		}{health.StatusOk}, true)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := reg.Run(r.Context())
+
+		log := sl.L(r.Context())
+
+		for name, result := range report.Checks {
+			if result.Error == "" {
+				continue
+			}
+
+			log.Warn("health: check failed", sl.StringAttr("check", name), sl.StringAttr("error", result.Error))
+
+			result.Error = ""
+			report.Checks[name] = result
+		}
+
+		writeReport(w, report, report.Ready && report.Status != health.StatusFail)
+	})
+
+	return mux
+}
+
+func writeReport(w http.ResponseWriter, report interface{}, ok bool) {
+	code := http.StatusOK
+	if !ok {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,59 @@
+package httper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nikitaSstepanov/tools/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler(t *testing.T) {
+	reg := health.New()
+	reg.Register("postgres", func(_ context.Context) error { return nil }, 0, true)
+	reg.Register("mail", func(_ context.Context) error { return errors.New("timeout") }, 0, false)
+
+	handler := HealthHandler(reg)
+
+	get := func(path string) (int, health.Report) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var report health.Report
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		return rec.Code, report
+	}
+
+	code, report := get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusOk, report.Status)
+	assert.Empty(t, report.Checks)
+
+	code, report = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, health.StatusFail, report.Checks["mail"].Status)
+	assert.Empty(t, report.Checks["mail"].Error)
+
+	reg.SetReady(false)
+
+	code, report = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, report.Ready)
+
+	reg.SetReady(true)
+	reg.Register("postgres", func(_ context.Context) error { return errors.New("down") }, 0, true)
+
+	code, _ = get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, report = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusFail, report.Status)
+}
@@ -0,0 +1,147 @@
+package httper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	e "github.com/nikitaSstepanov/tools/error"
+)
+
+// send does the request and reads the body, retrying up to c.retries times
+// while the failure is retryable (see e.Error.IsRetryable). Requests with
+// non-idempotent methods are retried only if the server asks for it with
+// 429, 503 or Retry-After, as they may have been processed otherwise.
+// Waits between attempts never exceed c.maxWait.
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	client := c.client.Load()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+
+		var body []byte
+
+		if err == nil {
+			body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+
+		if attempt >= c.retries || !canRetry(req, resp, err) {
+			return resp, body, err
+		}
+
+		outcome := classify(resp, err)
+		if outcome == nil || !outcome.IsRetryable() {
+			return resp, body, err
+		}
+
+		wait := outcome.GetRetryAfter()
+		if wait <= 0 {
+			wait = backoff(c.retryWait, attempt)
+		}
+
+		wait = min(wait, c.maxWait)
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return resp, body, err
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+}
+
+// backoff returns base doubled attempt times, stopping before it overflows.
+func backoff(base time.Duration, attempt int) time.Duration {
+	wait := base
+
+	for i := 0; i < attempt && wait > 0 && wait <= math.MaxInt64/2; i++ {
+		wait *= 2
+	}
+
+	return wait
+}
+
+// classify returns the failure of a request as e.Error, nil if it succeeded.
+// Transport errors are temporary, except for a done request context.
+func classify(resp *http.Response, err error) e.Error {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return e.New("", e.Internal, err).WithRetryable(false)
+		}
+
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return e.New("", e.Timeout, err).WithTemporary(true)
+		}
+
+		return e.New("", e.Unavailable, err).WithTemporary(true)
+	}
+
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	outcome := e.New("", e.FromHttpCode(resp.StatusCode))
+
+	if wait := retryAfter(resp.Header.Get("Retry-After")); wait > 0 {
+		outcome = outcome.WithRetryAfter(wait)
+	}
+
+	return outcome
+}
+
+func canRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+
+	}
+
+	if err != nil {
+		return false
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusServiceUnavailable ||
+		resp.Header.Get("Retry-After") != ""
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as an HTTP date.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds > math.MaxInt64/int(time.Second) {
+			return math.MaxInt64
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
@@ -13,10 +13,10 @@ import (
 
 // Config is type for server setup.
 type ServerCfg struct {
-	Url             string        `yaml:"url"             env:"SERVER_URL"              env-default:":80"`
-	ReadTimeout     time.Duration `yaml:"readTimeout"     env:"SERVER_READ_TIMEOUT"     env-default:"5s"`
-	WriteTimeout    time.Duration `yaml:"writeTimeout"    env:"SERVER_WRITE_TIMEOUT"    env-default:"5s"`
-	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"5s"`
+	Url             string        `yaml:"url"             env:"SERVER_URL"              env-default:":80" env-description:"Address the HTTP server listens on"`
+	ReadTimeout     time.Duration `yaml:"readTimeout"     env:"SERVER_READ_TIMEOUT"     env-default:"5s" env-description:"Timeout for reading a request"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout"    env:"SERVER_WRITE_TIMEOUT"    env-default:"5s" env-description:"Timeout for writing a response"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"5s" validate:"required,min=1s" env-description:"Time given to running requests on shutdown"`
 }
 
 type Server struct {
@@ -53,6 +53,11 @@ func (s *Server) Notify() <-chan error {
 	return s.notify
 }
 
+// Stop gracefully shuts the server down without waiting for a signal.
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	log := sl.L(ctx)
 
@@ -25,6 +25,8 @@ type (
 	Logger         = slog.Logger
 	Attr           = slog.Attr
 	Level          = slog.Level
+	Leveler        = slog.Leveler
+	LevelVar       = slog.LevelVar
 	Handler        = slog.Handler
 	Value          = slog.Value
 	HandlerOptions = slog.HandlerOptions
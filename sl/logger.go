@@ -1,6 +1,7 @@
 package sl
 
 import (
+	"io"
 	"log/slog"
 	"os"
 
@@ -12,41 +13,51 @@ import (
 type Config struct {
 	// Level specifies the logging level (e.g., info, debug, error).
 	// It can be set via YAML configuration or environment variable.
-	Level string `yaml:"level" env:"LOGGER_LEVEL" env-default:"info"`
+	Level string `yaml:"level" env:"LOGGER_LEVEL" env-default:"info" validate:"oneof=debug info warn error" env-description:"Minimal level of logged messages"`
 
 	// AddSource indicates whether to include the source of the log message (e.g., file and line number).
 	// This can be controlled through YAML or an environment variable.
-	AddSource bool ` yaml:"add_source" env:"LOGGER_ADD_SOURCE" env-default:"true"`
+	AddSource bool `yaml:"add_source" env:"LOGGER_ADD_SOURCE" env-default:"true" env-description:"Add file and line of the log call"`
 
 	// IsJSON determines if the log output should be in JSON format.
 	// If true, logs will be structured as JSON; otherwise, they will be plain text.
-	IsJSON bool `yaml:"is_json" env:"LOGGER_IS_JSON" env-default:"true"`
+	IsJSON bool `yaml:"is_json" env:"LOGGER_IS_JSON" env-default:"true" env-description:"Write JSON instead of text for the default logger type"`
 
 	// Writer specifies where the logs should be written.
 	// It can be a file path or a predefined output like "stdout".
-	Writer string `yaml:"writer" env:"LOGGER_WRITER" env-default:"stderr"`
+	Writer string `yaml:"writer" env:"LOGGER_WRITER" env-default:"stderr" validate:"oneof=stdout stderr file" env-description:"Where logs are written"`
 
 	// OutPath is the path to the output file if logging to a file.
 	// If left empty, logs will go to the Writer specified.
-	OutPath string `yaml:"out_path" env:"LOGGER_OUT_PATH" env-default:""`
+	OutPath string `yaml:"out_path" env:"LOGGER_OUT_PATH" env-default:"" env-description:"Directory of the log file if writer is file"`
 
 	// SetDefault indicates whether to set default logger options.
 	// This can be used to ensure that certain configurations are applied automatically.
-	SetDefault bool `yaml:"set_default" env:"LOGGER_SET_DEFAULT" env-default:"true"`
+	SetDefault bool `yaml:"set_default" env:"LOGGER_SET_DEFAULT" env-default:"true" env-description:"Make the logger the slog default"`
 
 	// Type defines the type of logger to use.
 	// It can be one of the following:
 	// - "pretty" or "dev": for human-readable logs with color and formatting.
 	// - "discard": to ignore all log messages.
 	// - "default": for standard logging behavior.
-	Type string `yaml:"type" env:"LOGGER_TYPE" env-default:"default"`
+	Type string `yaml:"type" env:"LOGGER_TYPE" env-default:"default" validate:"oneof=pretty dev discard default" env-description:"Logger output format"`
 }
 
 // New returns the slog.Logger with the specified configuration.
 func New(cfg *Config) *Logger {
-	handler := setupHandler(cfg)
+	logger, _ := Open(cfg)
 
-	logger := slog.New(handler)
+	return logger
+}
+
+// NewWithLevel works like New, but takes the level from level, which is set to cfg.Level.
+// Setting level later changes the level of the returned logger, e.g. on config reload.
+func NewWithLevel(cfg *Config, level *LevelVar) *Logger {
+	level.Set(ParseLevel(cfg.Level))
+
+	out, _ := setOut(cfg)
+
+	logger := slog.New(setupHandler(cfg, out, level))
 
 	if cfg.SetDefault {
 		SetDefault(logger)
@@ -55,12 +66,24 @@ func New(cfg *Config) *Logger {
 	return logger
 }
 
-func setupHandler(cfg *Config) Handler {
-	level := setLoggerLevel(cfg.Level)
+// Open works like New, but also returns a closer for the log output.
+// Closing it releases the log file when Writer is "file" and is a no-op otherwise.
+func Open(cfg *Config) (*Logger, io.Closer) {
+	out, closer := setOut(cfg)
 
-	opts := setHandlerOptions(level, cfg.AddSource)
+	handler := setupHandler(cfg, out, ParseLevel(cfg.Level))
+
+	logger := slog.New(handler)
+
+	if cfg.SetDefault {
+		SetDefault(logger)
+	}
+
+	return logger, closer
+}
 
-	out := setOut(cfg)
+func setupHandler(cfg *Config, out io.Writer, level Leveler) Handler {
+	opts := setHandlerOptions(level, cfg.AddSource)
 
 	var handler Handler
 
@@ -87,7 +110,9 @@ func setupHandler(cfg *Config) Handler {
 	return handler
 }
 
-func setLoggerLevel(lvl string) Level {
+// ParseLevel converts a level name from Config to Level.
+// Unknown names are treated as "info".
+func ParseLevel(lvl string) Level {
 	var level Level
 
 	switch lvl {
@@ -108,16 +133,24 @@ func setLoggerLevel(lvl string) Level {
 	return level
 }
 
-func setHandlerOptions(level Level, AddSource bool) *HandlerOptions {
+func setHandlerOptions(level Leveler, AddSource bool) *HandlerOptions {
 	return &HandlerOptions{AddSource: AddSource, Level: level}
 }
 
-func setOut(cfg *Config) *os.File {
+func setOut(cfg *Config) (io.Writer, io.Closer) {
 	if cfg.Writer == FileOut {
-		return getLogFile(cfg.OutPath)
+		file := getLogFile(cfg.OutPath)
+
+		return file, file
 	}
 
-	return os.Stderr
+	return os.Stderr, nopCloser{}
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
 }
 
 func getLogFile(path string) *os.File {
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"time"
 
-	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 	"github.com/nikitaSstepanov/tools/client/mail"
 	"github.com/nikitaSstepanov/tools/client/pg"
 	"github.com/nikitaSstepanov/tools/client/redis"
+	"github.com/nikitaSstepanov/tools/configurator/loader"
+	"github.com/nikitaSstepanov/tools/health"
 	"github.com/nikitaSstepanov/tools/httper"
 	"github.com/nikitaSstepanov/tools/migrate"
 	"github.com/nikitaSstepanov/tools/sl"
@@ -18,10 +20,126 @@ import (
 )
 
 var (
+	// ConfigPath is the config path of the default instance, set by Init.
 	ConfigPath = "config/config.yaml"
-	config     = &toolsConfig{}
+
+	// StrictConfig makes Init fail on unknown keys inside the sections read by tools.
+	// Top-level keys are not checked, as they may belong to the application config.
+	StrictConfig = false
+
+	std = &Tools{
+		config: &Config{},
+		health: health.New(),
+	}
 )
 
+// checkTimeout limits each run of the health checks registered by tools.
+const checkTimeout = 2 * time.Second
+
+// Tools holds a config and builds clients from it. Unlike the package functions,
+// which share the default instance, several instances with different configs
+// may be used side by side, e.g. in parallel tests.
+type Tools struct {
+	configPath string
+	dotenv     string
+	env        string
+	envSet     bool
+	strict     bool
+	config     *Config
+	resolved   loader.Resolved
+	health     *health.Registry
+}
+
+// Option configures an instance created by New.
+type Option func(t *Tools)
+
+// WithConfigPath sets the config file. By default CONFIG_PATH or config/config.yaml is used.
+func WithConfigPath(path string) Option {
+	return func(t *Tools) {
+		t.configPath = path
+	}
+}
+
+// WithDotenv loads the .env file at path into the environment before the config is read.
+// Note that the environment is shared by the whole process.
+func WithDotenv(path string) Option {
+	return func(t *Tools) {
+		t.dotenv = path
+	}
+}
+
+// WithEnv sets the environment whose overlay is applied, instead of APP_ENV.
+func WithEnv(env string) Option {
+	return func(t *Tools) {
+		t.env = env
+		t.envSet = true
+	}
+}
+
+// WithStrict makes New fail on unknown keys inside the sections read by tools.
+func WithStrict() Option {
+	return func(t *Tools) {
+		t.strict = true
+	}
+}
+
+// New reads the config with the same precedence as Init and returns
+// an instance with its own config and health registry. Like in Init, the .env file
+// is loaded first, so it may set CONFIG_PATH and APP_ENV; options still win over them.
+func New(opts ...Option) (*Tools, error) {
+	t := &Tools{
+		health: health.New(),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if t.dotenv != "" {
+		if err := godotenv.Load(t.dotenv); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.configPath == "" {
+		t.configPath = os.Getenv("CONFIG_PATH")
+	}
+
+	if t.configPath == "" {
+		t.configPath = "config/config.yaml"
+	}
+
+	if !t.envSet {
+		t.env = os.Getenv(loader.EnvVar)
+	}
+
+	var cfg Config
+
+	resolved, err := loader.Read(t.configPath, &cfg, t.options(true))
+	if err != nil {
+		return nil, err
+	}
+
+	t.config = &cfg
+	t.resolved = resolved
+
+	return t, nil
+}
+
+// Default returns the instance used by the package functions.
+func Default() *Tools {
+	return std
+}
+
+// Init reads the config. Sources are applied from lowest to highest precedence:
+//
+//  1. the file at ConfigPath (CONFIG_PATH or path, if given);
+//  2. the overlay for the APP_ENV environment, e.g. config/config.prod.yaml, if it exists;
+//  3. the .env file, if useDotenv is set;
+//  4. environment variables;
+//  5. env-default values for fields that are still empty.
+//
+// If some fields are missing or invalid, Init returns *loader.ValidationError listing all of them.
 func Init(useDotenv bool, path ...string) error {
 	if len(path) > 1 {
 		return errors.New("there should be only one config path")
@@ -41,58 +159,158 @@ func Init(useDotenv bool, path ...string) error {
 		ConfigPath = path[0]
 	}
 
-	var cfg toolsConfig
+	std.configPath = ConfigPath
+	std.env = os.Getenv(loader.EnvVar)
+	std.strict = StrictConfig
+
+	var cfg Config
 
-	if err := cleanenv.ReadConfig(ConfigPath, &cfg); err != nil {
-		return nil
+	resolved, err := loader.Read(ConfigPath, &cfg, std.options(true))
+	if err != nil {
+		return err
 	}
 
-	config = &cfg
+	std.config = &cfg
+	std.resolved = resolved
 
 	return nil
 }
 
+// ConfigPath returns the config file of the instance.
+func (t *Tools) ConfigPath() string {
+	return t.configPath
+}
+
+// Get reads the config file of the instance into cfg, like configurator.Get.
+func (t *Tools) Get(cfg interface{}) error {
+	_, err := loader.Read(t.configPath, cfg, t.options(false))
+
+	return err
+}
+
+// Section reads the subtree under key of the config file of the instance into cfg,
+// like configurator.Section.
+func (t *Tools) Section(key string, cfg interface{}) error {
+	_, err := loader.ReadSection(t.configPath, key, cfg, t.options(false))
+
+	return err
+}
+
+func (t *Tools) options(own bool) loader.Options {
+	if !own {
+		return loader.Options{Env: t.env}
+	}
+
+	return loader.Options{
+		Strict:           t.strict,
+		AllowUnknownRoot: true,
+		Env:              t.env,
+	}
+}
+
+// DumpConfig returns the effective config as YAML with secrets redacted.
+func DumpConfig() ([]byte, error) {
+	return std.DumpConfig()
+}
+
+// DumpConfig returns the effective config as YAML with secrets
+// and values read from references redacted.
+func (t *Tools) DumpConfig() ([]byte, error) {
+	return loader.Dump(t.config, t.resolved)
+}
+
+// Health returns the registry the checks of clients built by tools are added to.
+// Serve it with httper.HealthHandler.
+func Health() *health.Registry {
+	return std.Health()
+}
+
+// Health returns the registry the checks of clients built by the instance are added to.
+func (t *Tools) Health() *health.Registry {
+	return t.health
+}
+
+// Pg connects to postgres and registers the critical "postgres" health check.
 func Pg() (pg.Client, error) {
+	return std.Pg()
+}
+
+// Pg connects to postgres and registers the critical "postgres" health check.
+func (t *Tools) Pg() (pg.Client, error) {
 	ctx := context.Background()
 
-	postgres, err := pg.New(ctx, &config.Postgres)
+	postgres, err := pg.New(ctx, &t.config.Postgres)
 	if err != nil {
 		return nil, err
 	}
 
-	if config.Postgres.MigrationsRun {
-		err := migrate.MigratePg(postgres.ToPgx(), config.Postgres.MigrationsPath)
+	if t.config.Postgres.MigrationsRun {
+		err := migrate.MigratePg(postgres.ToPgx(), t.config.Postgres.MigrationsPath)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	t.health.Register("postgres", postgres.Ping, checkTimeout, true)
+
 	return postgres, nil
 }
 
+// Redis connects to redis and registers the critical "redis" health check.
 func Redis() (redis.Client, error) {
+	return std.Redis()
+}
+
+// Redis connects to redis and registers the critical "redis" health check.
+func (t *Tools) Redis() (redis.Client, error) {
 	ctx := context.Background()
 
-	rs, err := redis.New(ctx, &config.Redis)
+	rs, err := redis.New(ctx, &t.config.Redis)
 	if err != nil {
 		return redis.Client{}, err
 	}
 
+	t.health.Register("redis", func(ctx context.Context) error {
+		return rs.Ping(ctx).Err()
+	}, checkTimeout, true)
+
 	return rs, nil
 }
 
 func Sl() *sl.Logger {
-	return sl.New(&config.Logger)
+	return std.Sl()
+}
+
+func (t *Tools) Sl() *sl.Logger {
+	return sl.New(&t.config.Logger)
 }
 
 func HttpServer(handler http.Handler) *httper.Server {
-	return httper.NewServer(&config.HttpServer, handler)
+	return std.HttpServer(handler)
 }
 
+func (t *Tools) HttpServer(handler http.Handler) *httper.Server {
+	return httper.NewServer(&t.config.HttpServer, handler)
+}
+
+// Mail builds the mail client and registers the non-critical "mail" health check.
 func Mail() *mail.Client {
-	return mail.New(&config.Mail)
+	return std.Mail()
+}
+
+// Mail builds the mail client and registers the non-critical "mail" health check.
+func (t *Tools) Mail() *mail.Client {
+	client := mail.New(&t.config.Mail)
+
+	t.health.Register("mail", client.Ping, checkTimeout, false)
+
+	return client
 }
 
 func Coder() *coder.Coder {
-	return coder.New(&config.Coder)
+	return std.Coder()
+}
+
+func (t *Tools) Coder() *coder.Coder {
+	return coder.New(&t.config.Coder)
 }
@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nikitaSstepanov/tools/configurator/loader"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func useConfig(t *testing.T, data string) {
+	t.Helper()
+
+	path := writeConfig(t, data)
+
+	prevPath, prevStd := ConfigPath, *std
+
+	t.Cleanup(func() {
+		ConfigPath, *std = prevPath, prevStd
+	})
+
+	t.Setenv("CONFIG_PATH", path)
+}
+
+func TestInitDefaults(t *testing.T) {
+	useConfig(t, "app_name: test\n")
+
+	assert.NoError(t, Init(false))
+	assert.Equal(t, "disable", std.config.Postgres.SSLMode)
+	assert.Equal(t, 6379, std.config.Redis.Port)
+	assert.Equal(t, 10, std.config.Coder.HashCost)
+}
+
+func TestInitInvalid(t *testing.T) {
+	useConfig(t, `
+postgres:
+  port: 70000
+  sslmode: disabled
+redis:
+  port: abc
+logger:
+  level: verbose
+http_server:
+  shutdownTimeout: 0s
+`)
+
+	t.Setenv("HASH_COST", "-1")
+	t.Setenv("ENCRYPT_SECRET", "too short")
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT", "0s")
+
+	err := Init(false)
+
+	var vErr *loader.ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *loader.ValidationError, got %v", err)
+	}
+
+	paths := make([]string, 0)
+	for _, fErr := range vErr.Errors {
+		paths = append(paths, fErr.Path)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"postgres.port",
+		"postgres.sslmode",
+		"redis.port",
+		"logger.level",
+		"http_server.shutdownTimeout",
+		"coder.secret",
+		"coder.hashcost",
+	}, paths)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		port   int
+	}{
+		{name: "first", config: "redis:\n  port: 6380\n", port: 6380},
+		{name: "second", config: "redis:\n  port: 6381\n", port: 6381},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeConfig(t, tt.config)
+
+			tools, err := New(WithConfigPath(path))
+
+			assert.NoError(t, err)
+			assert.Equal(t, path, tools.ConfigPath())
+			assert.Equal(t, tt.port, tools.config.Redis.Port)
+			assert.NotSame(t, Health(), tools.Health())
+
+			var app struct {
+				Redis struct {
+					Port int `yaml:"port"`
+				} `yaml:"redis"`
+			}
+
+			assert.NoError(t, tools.Get(&app))
+			assert.Equal(t, tt.port, app.Redis.Port)
+		})
+	}
+}
+
+func TestNewStrict(t *testing.T) {
+	path := writeConfig(t, "redis:\n  prot: 6380\n")
+
+	_, err := New(WithConfigPath(path))
+	assert.NoError(t, err)
+
+	_, err = New(WithConfigPath(path), WithStrict())
+
+	var vErr *loader.ValidationError
+	if assert.ErrorAs(t, err, &vErr) {
+		assert.Equal(t, "redis.prot", vErr.Errors[0].Path)
+	}
+}
+
+func TestNewDotenv(t *testing.T) {
+	for _, key := range []string{"CONFIG_PATH", loader.EnvVar} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	path := writeConfig(t, "redis:\n  port: 6380\n")
+	overlay := loader.OverlayPath(path, "prod")
+
+	if err := os.WriteFile(overlay, []byte("redis:\n  port: 6381\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dotenv := filepath.Join(t.TempDir(), ".env")
+	data := "CONFIG_PATH=" + path + "\n" + loader.EnvVar + "=prod\n"
+
+	if err := os.WriteFile(dotenv, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tools, err := New(WithDotenv(dotenv))
+
+	assert.NoError(t, err)
+	assert.Equal(t, path, tools.ConfigPath())
+	assert.Equal(t, 6381, tools.config.Redis.Port)
+
+	tools, err = New(WithDotenv(dotenv), WithEnv(""))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6380, tools.config.Redis.Port)
+}
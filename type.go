@@ -9,11 +9,14 @@ import (
 	"github.com/nikitaSstepanov/tools/utils/coder"
 )
 
-type toolsConfig struct {
+// Config is the config read by Init and New: the sections of the clients built by tools.
+// It is exported for tools that describe it, like cmd/configdoc.
+type Config struct {
 	Postgres   pg.Config        `yaml:"postgres"`
 	Redis      redis.Config     `yaml:"redis"`
 	Logger     sl.Config        `yaml:"logger"`
 	HttpServer httper.ServerCfg `yaml:"http_server"`
 	Mail       mail.Config      `yaml:"mail"`
 	Coder      coder.Config     `yaml:"coder"`
+	App        AppCfg           `yaml:"app"`
 }
@@ -10,8 +10,8 @@ import (
 )
 
 type Config struct {
-	Secret   string `env:"ENCRYPT_SECRET" env-default:""`
-	HashCost int    `env:"HASH_COST" env-default:"10"`
+	Secret   string `env:"ENCRYPT_SECRET" env-default:"" secret:"true" validate:"len=16|24|32" env-description:"AES key used by Encrypt and Decrypt"`
+	HashCost int    `env:"HASH_COST" env-default:"10" validate:"min=4,max=31" env-description:"bcrypt cost used by Hash"`
 }
 
 type Coder struct {